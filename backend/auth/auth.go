@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// Errors returned by Authenticate. ErrMissingCredentials means the request
+// carried none; every other error means the credentials were rejected.
+var (
+	ErrMissingCredentials = errors.New(constants.ErrMissingCredentials)
+	ErrInvalidAPIKey      = errors.New(constants.ErrInvalidAPIKey)
+	ErrInvalidToken       = errors.New(constants.ErrInvalidToken)
+	ErrTokenExpired       = errors.New(constants.ErrTokenExpired)
+)
+
+// APIKeyHeader carries static API keys. Keys are also accepted as
+// "Authorization: ApiKey <key>".
+const APIKeyHeader = "X-API-Key"
+
+// Config lists the credentials an Authenticator accepts. JWTs are only
+// accepted for the algorithms whose key is set.
+type Config struct {
+	// APIKeys maps each static key to the principal it authenticates
+	APIKeys map[string]models.Principal
+	// HMACSecret verifies HS256 tokens
+	HMACSecret []byte
+	// RSAPublicKey verifies RS256 tokens
+	RSAPublicKey *rsa.PublicKey
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// Authenticator resolves the principal of a request from its API key or
+// bearer token.
+type Authenticator struct {
+	apiKeys map[[sha256.Size]byte]models.Principal
+	config  Config
+	now     func() time.Time
+}
+
+// NewAuthenticator returns an Authenticator for config
+func NewAuthenticator(config Config) *Authenticator {
+	a := &Authenticator{
+		apiKeys: make(map[[sha256.Size]byte]models.Principal, len(config.APIKeys)),
+		config:  config,
+		now:     time.Now,
+	}
+	// Keys are looked up by digest so that lookups take the same time
+	// whatever prefix of a valid key the caller guessed
+	for key, principal := range config.APIKeys {
+		principal.Method = models.AuthAPIKey
+		a.apiKeys[sha256.Sum256([]byte(key))] = principal
+	}
+	return a
+}
+
+// Enabled reports whether any credential is configured. Without one no
+// request can authenticate.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.apiKeys) > 0 || len(a.config.HMACSecret) > 0 || a.config.RSAPublicKey != nil)
+}
+
+// Authenticate returns the principal identified by the credentials of r
+func (a *Authenticator) Authenticate(r *http.Request) (*models.Principal, error) {
+	if key := APIKey(r); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	credentials = strings.TrimSpace(credentials)
+	switch {
+	case credentials == "":
+		return nil, ErrMissingCredentials
+	case strings.EqualFold(scheme, "Bearer"):
+		return a.authenticateToken(credentials)
+	default:
+		return nil, ErrMissingCredentials
+	}
+}
+
+// APIKey returns the static API key r carries, whether valid or not, or ""
+// when it has none
+func APIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(credentials)
+	}
+	return ""
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*models.Principal, error) {
+	principal, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	principal.Roles = append([]string(nil), principal.Roles...)
+	return &principal, nil
+}
+
+func (a *Authenticator) authenticateToken(token string) (*models.Principal, error) {
+	claims, err := a.verifyJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Principal{Subject: claims.Subject, Roles: claims.Roles, Method: models.AuthJWT}, nil
+}
+
+// ParseAPIKeys reads a comma-separated list of subject:key pairs, each
+// optionally followed by :role or :role|role granting the key's roles
+func ParseAPIKeys(spec string) (map[string]models.Principal, error) {
+	keys := make(map[string]models.Principal)
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid API key entry %q (expected subject:key[:roles])", entry)
+		}
+		subject, key := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if _, dup := keys[key]; dup {
+			return nil, fmt.Errorf("API key of %s is used twice", subject)
+		}
+
+		principal := models.Principal{Subject: subject}
+		if len(parts) == 3 {
+			roles, err := parseRoles(parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("API key of %s: %v", subject, err)
+			}
+			principal.Roles = roles
+		}
+		keys[key] = principal
+	}
+	return keys, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal set by WithPrincipal, if any
+func PrincipalFromContext(ctx context.Context) (*models.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*models.Principal)
+	return principal, ok && principal != nil
+}
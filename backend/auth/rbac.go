@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"book-library-backend/models"
+)
+
+// Roles a principal can hold. Principals without a role are readers.
+const (
+	RoleAdmin     = "admin"
+	RoleLibrarian = "librarian"
+	RoleReader    = "reader"
+)
+
+// Roles lists every known role
+var Roles = []string{RoleAdmin, RoleLibrarian, RoleReader}
+
+// Actions guarded by a Policy
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionImport     = "import"
+	ActionProcessURL = "process-url"
+	ActionShelve     = "shelve"
+)
+
+// Policy maps each guarded action to the roles allowed to perform it.
+// Actions missing from the policy are denied to everyone.
+type Policy map[string][]string
+
+// DefaultPolicy lets librarians curate the catalog while deleting (and
+// restoring) books stays with admins. Readers may only use the URL tools
+// and their own shelf.
+var DefaultPolicy = Policy{
+	ActionCreate:     {RoleAdmin, RoleLibrarian},
+	ActionUpdate:     {RoleAdmin, RoleLibrarian},
+	ActionDelete:     {RoleAdmin},
+	ActionImport:     {RoleAdmin, RoleLibrarian},
+	ActionProcessURL: {RoleAdmin, RoleLibrarian, RoleReader},
+	ActionShelve:     {RoleAdmin, RoleLibrarian, RoleReader},
+}
+
+// RouteActions maps the "METHOD /path/template" of each guarded mux route to
+// the actions it performs. Every route with a method other than GET, HEAD or
+// OPTIONS must be listed, Authorize denies the ones that are not. A batch can
+// create, update and delete, so it needs all three.
+var RouteActions = map[string][]string{
+	"POST /api/books":              {ActionCreate},
+	"POST /api/books/batch":        {ActionCreate, ActionUpdate, ActionDelete},
+	"POST /api/books/import":       {ActionImport},
+	"PUT /api/books/{id}":          {ActionUpdate},
+	"PATCH /api/books/{id}":        {ActionUpdate},
+	"DELETE /api/books/{id}":       {ActionDelete},
+	"POST /api/books/{id}/restore": {ActionDelete},
+	"POST /api/process-url":        {ActionProcessURL},
+	"PUT /api/me/books/{id}":       {ActionShelve},
+	"DELETE /api/me/books/{id}":    {ActionShelve},
+}
+
+// Allows reports whether principal holds a role allowed to perform action
+func (p Policy) Allows(principal *models.Principal, action string) bool {
+	roles := principal.Roles
+	if len(roles) == 0 {
+		roles = []string{RoleReader}
+	}
+	for _, allowed := range p[action] {
+		for _, role := range roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Denied returns the first of actions that principal may not perform, or ""
+// when every action is allowed
+func (p Policy) Denied(principal *models.Principal, actions []string) string {
+	for _, action := range actions {
+		if !p.Allows(principal, action) {
+			return action
+		}
+	}
+	return ""
+}
+
+// IsSafeMethod reports whether method only reads, so that routes serving it
+// need no entry in RouteActions
+func IsSafeMethod(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	for _, valid := range Roles {
+		if role == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRoles reads a "|"-separated list of roles
+func parseRoles(spec string) ([]string, error) {
+	var roles []string
+	for _, role := range strings.Split(spec, "|") {
+		role = strings.TrimSpace(role)
+		if !IsValidRole(role) {
+			return nil, fmt.Errorf("unknown role %q (expected one of %s)", role, strings.Join(Roles, ", "))
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"book-library-backend/auth"
+	"book-library-backend/middleware"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config is the complete application configuration. Every setting can come
+// from a YAML or TOML file, from the environment variable in its env tag or
+// from the command-line flag in its flag tag, see Load. Settings tagged
+// secret are redacted when printed.
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Log       LogConfig       `yaml:"log" toml:"log"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	Trash     TrashConfig     `yaml:"trash" toml:"trash"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Addr            string        `yaml:"addr" toml:"addr" env:"SERVER_ADDR" flag:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout"`
+}
+
+// LogConfig configures logrus
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log-level"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT" flag:"log-format"`
+}
+
+// DatabaseConfig selects the book store
+type DatabaseConfig struct {
+	Driver        string `yaml:"driver" toml:"driver" env:"DB_DRIVER" flag:"db-driver"`
+	Path          string `yaml:"path" toml:"path" env:"DB_PATH" flag:"db-path"`
+	MigrateDryRun bool   `yaml:"migrate_dry_run" toml:"migrate_dry_run" env:"DB_MIGRATE_DRY_RUN" flag:"migrate-dry-run"`
+}
+
+// TrashConfig configures the purge of trashed books. A zero retention keeps
+// them forever.
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention" env:"TRASH_RETENTION" flag:"trash-retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval" env:"TRASH_PURGE_INTERVAL" flag:"trash-purge-interval"`
+}
+
+// ErrNoCredentials is returned by AuthConfig.Authenticator when no
+// credential is configured and authentication was not explicitly disabled
+var ErrNoCredentials = errors.New("no credentials configured: set auth.api_keys, auth.jwt_secret or auth.jwt_public_key, or auth.disabled: true to serve every request anonymously")
+
+// AuthConfig lists the accepted credentials. At least one API key, JWT
+// secret or JWT public key is required unless Disabled is set.
+type AuthConfig struct {
+	// Disabled serves every request anonymously, it must be set explicitly
+	Disabled bool `yaml:"disabled" toml:"disabled" env:"AUTH_DISABLED" flag:"auth-disabled"`
+	// APIKeys holds subject:key entries, optionally followed by :role|role
+	APIKeys      []string      `yaml:"api_keys" toml:"api_keys" env:"AUTH_API_KEYS" flag:"auth-api-keys" secret:"true"`
+	JWTSecret    string        `yaml:"jwt_secret" toml:"jwt_secret" env:"AUTH_JWT_SECRET" flag:"auth-jwt-secret" secret:"true"`
+	JWTPublicKey string        `yaml:"jwt_public_key" toml:"jwt_public_key" env:"AUTH_JWT_PUBLIC_KEY" flag:"auth-jwt-public-key"`
+	JWTIssuer    string        `yaml:"jwt_issuer" toml:"jwt_issuer" env:"AUTH_JWT_ISSUER" flag:"auth-jwt-issuer"`
+	JWTAudience  string        `yaml:"jwt_audience" toml:"jwt_audience" env:"AUTH_JWT_AUDIENCE" flag:"auth-jwt-audience"`
+	Leeway       time.Duration `yaml:"leeway" toml:"leeway" env:"AUTH_JWT_LEEWAY" flag:"auth-jwt-leeway"`
+}
+
+// RateLimitConfig holds limits written N/s, N/m, N/h or off
+type RateLimitConfig struct {
+	Default string `yaml:"default" toml:"default" env:"RATE_LIMIT_DEFAULT" flag:"rate-limit"`
+	// Routes holds "METHOD /path/template=limit" entries
+	Routes []string `yaml:"routes" toml:"routes" env:"RATE_LIMIT_ROUTES" flag:"rate-limit-routes"`
+	// AuthFailures limits the failed authentications of each remote IP
+	AuthFailures string        `yaml:"auth_failures" toml:"auth_failures" env:"RATE_LIMIT_AUTH_FAILURES" flag:"rate-limit-auth-failures"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"RATE_LIMIT_IDLE_TIMEOUT" flag:"rate-limit-idle-timeout"`
+	// MaxBuckets caps the client buckets held in memory
+	MaxBuckets int `yaml:"max_buckets" toml:"max_buckets" env:"RATE_LIMIT_MAX_BUCKETS" flag:"rate-limit-max-buckets"`
+}
+
+// CORSConfig configures cross-origin requests, see middleware.CORSConfig
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins"`
+	AllowCredentials bool          `yaml:"allow_credentials" toml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" toml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" flag:"cors-exposed-headers"`
+	MaxAge           time.Duration `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE" flag:"cors-max-age"`
+}
+
+// Default returns the configuration used for every setting left unset
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Log:      LogConfig{Level: "info", Format: "json"},
+		Database: DatabaseConfig{Driver: "memory", Path: "library.db"},
+		Trash:    TrashConfig{Retention: 720 * time.Hour, PurgeInterval: time.Hour},
+		Auth:     AuthConfig{Leeway: 30 * time.Second},
+		RateLimit: RateLimitConfig{
+			Default:      "300/m",
+			Routes:       []string{"POST /api/books=30/m", "POST /api/process-url=60/m"},
+			AuthFailures: "10/m",
+			IdleTimeout:  10 * time.Minute,
+			MaxBuckets:   middleware.DefaultMaxRateLimitBuckets,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			AllowedHeaders: append([]string(nil), middleware.DefaultCORSAllowedHeaders...),
+			ExposedHeaders: append([]string(nil), middleware.DefaultCORSExposedHeaders...),
+			MaxAge:         24 * time.Hour,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr: %v", err))
+	}
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %v", err))
+	}
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format must be json or text, got %q", c.Log.Format)
+
+	check(c.Database.Driver == "memory" || c.Database.Driver == "sqlite",
+		"database.driver must be memory or sqlite, got %q", c.Database.Driver)
+	check(c.Database.Driver != "sqlite" || c.Database.Path != "", "database.path is required with the sqlite driver")
+
+	check(c.Trash.Retention >= 0, "trash.retention must not be negative")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval must be positive")
+
+	check(c.Auth.Leeway >= 0, "auth.leeway must not be negative")
+	if authenticator, err := c.Auth.build(); err != nil {
+		errs = append(errs, fmt.Errorf("auth: %v", err))
+	} else {
+		check(!c.Auth.Disabled || !authenticator.Enabled(), "auth.disabled must not be set together with credentials")
+	}
+	check(c.RateLimit.IdleTimeout > 0, "rate_limit.idle_timeout must be positive")
+	check(c.RateLimit.MaxBuckets > 0, "rate_limit.max_buckets must be positive")
+	if _, err := c.RateLimit.Limiter(); err != nil {
+		errs = append(errs, fmt.Errorf("rate_limit: %v", err))
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+	if _, err := c.CORS.Policy(); err != nil {
+		errs = append(errs, fmt.Errorf("cors: %v", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Authenticator builds the authenticator accepting the configured
+// credentials. It returns nil when authentication is disabled and
+// ErrNoCredentials when no credential is set, so that the server fails closed.
+func (c AuthConfig) Authenticator() (*auth.Authenticator, error) {
+	if c.Disabled {
+		return nil, nil
+	}
+	authenticator, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	if !authenticator.Enabled() {
+		return nil, ErrNoCredentials
+	}
+	return authenticator, nil
+}
+
+// build parses the credentials, reading the JWT public key file when one is
+// set
+func (c AuthConfig) build() (*auth.Authenticator, error) {
+	apiKeys, err := auth.ParseAPIKeys(strings.Join(c.APIKeys, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	config := auth.Config{
+		APIKeys:    apiKeys,
+		HMACSecret: []byte(c.JWTSecret),
+		Issuer:     c.JWTIssuer,
+		Audience:   c.JWTAudience,
+		Leeway:     c.Leeway,
+	}
+	if c.JWTPublicKey != "" {
+		if config.RSAPublicKey, err = auth.LoadRSAPublicKey(c.JWTPublicKey); err != nil {
+			return nil, err
+		}
+	}
+	return auth.NewAuthenticator(config), nil
+}
+
+// Limiter builds the rate limiter enforcing the configured limits
+func (c RateLimitConfig) Limiter() (*middleware.RateLimiter, error) {
+	defaultLimit, err := middleware.ParseRateLimit(c.Default)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := middleware.ParseRouteRateLimits(strings.Join(c.Routes, ","))
+	if err != nil {
+		return nil, err
+	}
+	authFailures, err := middleware.ParseRateLimit(c.AuthFailures)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.NewRateLimiter(middleware.RateLimiterConfig{
+		Default:      defaultLimit,
+		Routes:       routes,
+		AuthFailures: authFailures,
+		IdleTimeout:  c.IdleTimeout,
+		MaxBuckets:   c.MaxBuckets,
+	}), nil
+}
+
+// Policy builds the configured CORS policy
+func (c CORSConfig) Policy() (*middleware.CORS, error) {
+	return middleware.NewCORS(middleware.CORSConfig{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowCredentials: c.AllowCredentials,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		MaxAge:           c.MaxAge,
+	})
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable pointing at the configuration file
+// when the --config flag is not given
+const FileEnv = "CONFIG_FILE"
+
+// redacted replaces secret values when the configuration is printed
+const redacted = "REDACTED"
+
+// Options are the command-line switches that are not settings themselves
+type Options struct {
+	// File is the configuration file that was loaded, if any
+	File string
+	// PrintConfig asks for the effective configuration to be printed
+	PrintConfig bool
+}
+
+// Load builds the configuration from, in increasing order of precedence,
+// the defaults, the YAML or TOML file named by --config or CONFIG_FILE, the
+// environment variables read with lookupEnv and the flags in args. Empty
+// environment variables are ignored. The result is validated.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, Options, error) {
+	var opts Options
+	cfg := Default()
+	fields := settings(cfg)
+
+	// Flags are only collected here; they are applied last
+	flags := flag.NewFlagSet("book-library-backend", flag.ContinueOnError)
+	flags.StringVar(&opts.File, "config", "", "YAML (.yaml, .yml) or TOML (.toml) configuration file (env "+FileEnv+")")
+	flags.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	given := make(map[string]string)
+	for _, f := range fields {
+		name := f.flag
+		usage := fmt.Sprintf("%s (env %s)", f.path, f.env)
+		collect := func(value string) error {
+			given[name] = value
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			flags.BoolFunc(name, usage, collect)
+		} else {
+			flags.Func(name, usage, collect)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, opts, err
+	}
+	if flags.NArg() > 0 {
+		return nil, opts, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if opts.File == "" {
+		opts.File, _ = lookupEnv(FileEnv)
+	}
+	if opts.File != "" {
+		if err := loadFile(opts.File, cfg); err != nil {
+			return nil, opts, err
+		}
+	}
+
+	for _, f := range fields {
+		if value, ok := lookupEnv(f.env); ok && value != "" {
+			if err := f.set(value); err != nil {
+				return nil, opts, fmt.Errorf("%s: %v", f.env, err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if value, ok := given[f.flag]; ok {
+			if err := f.set(value); err != nil {
+				return nil, opts, fmt.Errorf("--%s: %v", f.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, opts, fmt.Errorf("invalid configuration:\n%v", err)
+	}
+	return cfg, opts, nil
+}
+
+// loadFile decodes the file at path into cfg, picking the format from the
+// extension. Keys that match no setting are rejected.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid configuration file %s: %v", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration file %s: %v", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("invalid configuration file %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("configuration file %s must end in .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// WriteYAML writes the configuration as YAML with secrets redacted
+func (c *Config) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Redacted returns a copy of c whose non-empty secrets are replaced
+func (c *Config) Redacted() *Config {
+	copied := *c
+	for _, f := range settings(&copied) {
+		if !f.secret {
+			continue
+		}
+		switch f.value.Kind() {
+		case reflect.String:
+			if f.value.String() != "" {
+				f.value.SetString(redacted)
+			}
+		case reflect.Slice:
+			masked := make([]string, f.value.Len())
+			for i := range masked {
+				masked[i] = redacted
+			}
+			f.value.Set(reflect.ValueOf(masked))
+		}
+	}
+	return &copied
+}
+
+// setting is one leaf of Config with the names it can be set under
+type setting struct {
+	path   string
+	env    string
+	flag   string
+	secret bool
+	value  reflect.Value
+}
+
+// settings lists the leaves of cfg in declaration order
+func settings(cfg *Config) []setting {
+	var fields []setting
+	var walk func(prefix string, value reflect.Value)
+	walk = func(prefix string, value reflect.Value) {
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			if field.Type.Kind() == reflect.Struct {
+				walk(name, value.Field(i))
+				continue
+			}
+			fields = append(fields, setting{
+				path:   name,
+				env:    field.Tag.Get("env"),
+				flag:   field.Tag.Get("flag"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  value.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return fields
+}
+
+// set parses raw into the setting. Durations use Go syntax and lists are
+// comma-separated.
+func (f setting) set(raw string) error {
+	switch {
+	case f.value.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %s for %s", f.value.Type(), f.path))
+	}
+	return nil
+}
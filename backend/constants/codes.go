@@ -0,0 +1,41 @@
+package constants
+
+// Error codes returned in the code field of error responses. Clients should
+// branch on these rather than on the human-readable messages.
+const (
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeInvalidJSON          = "invalid_json"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeValidationFailed     = "validation_failed"
+	CodeInvalidPatch         = "invalid_patch"
+	CodeInvalidImport        = "invalid_import"
+	CodeInvalidURL           = "invalid_url"
+	CodeBookNotFound         = "book_not_found"
+	CodeBookAlreadyExists    = "book_already_exists"
+	CodeDuplicateISBN        = "duplicate_isbn"
+	CodeBookNotInTrash       = "book_not_in_trash"
+	CodeUserBookNotFound     = "user_book_not_found"
+	CodeVersionMismatch      = "version_mismatch"
+	CodeBatchRolledBack      = "batch_rolled_back"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+)
+
+// Field error codes name the rule a request field failed
+const (
+	FieldRequired  = "required"
+	FieldMin       = "min"
+	FieldMax       = "max"
+	FieldOneOf     = "oneof"
+	FieldISBN      = "isbn"
+	FieldURL       = "url"
+	FieldNotFuture = "notfuture"
+	FieldNotBefore = "notbefore"
+	FieldUnknown   = "unknown"
+	FieldType      = "type"
+)
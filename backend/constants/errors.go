@@ -6,6 +6,12 @@ const (
 	ErrCreatingBook           = "error creating book"
 	ErrBookNotFound           = "book not found"
 	ErrBookAlreadyExists      = "book already exists"
+	ErrVersionMismatch        = "book was modified by another request"
+	ErrBatchRolledBack        = "not applied: batch was rolled back"
+	ErrBookNotInTrash         = "book is not in the trash"
+	ErrDuplicateISBN          = "another book already has this ISBN"
+	ErrUserNotFound           = "user not found"
+	ErrUserBookNotFound       = "book is not on your shelf"
 )
 
 // HTTP error messages
@@ -15,15 +21,129 @@ const (
 	ErrMissingParameters = "missing required parameters"
 	ErrUnauthorized      = "unauthorized access"
 	ErrForbidden         = "forbidden access"
+	ErrRateLimited       = "too many requests, retry later"
 	ErrInternalServer    = "internal server error"
 	ErrFetchingBooks     = "error fetching books"
 )
 
+// Request body error messages
+const (
+	ErrUnsupportedBody = "request body must be application/json"
+	ErrBodyTooLarge    = "request body is too large (max 1 MB)"
+	ErrEmptyBody       = "request body must not be empty"
+	ErrTrailingData    = "request body must contain a single JSON value"
+	ErrUnknownField    = "unknown field"
+	ErrFieldType       = "wrong type for field"
+)
+
+// Authentication error messages
+const (
+	ErrMissingCredentials = "missing API key or bearer token"
+	ErrInvalidAPIKey      = "invalid API key"
+	ErrInvalidToken       = "invalid bearer token"
+	ErrTokenExpired       = "bearer token has expired"
+	ErrActorMismatch      = "X-Actor must match the authenticated principal"
+	ErrNoCurrentUser      = "sign in to use your shelf"
+	ErrActionNotAllowed   = "%s requires one of the roles %s"
+	ErrRouteNotGuarded    = "no authorization rule for %s %s"
+)
+
+// CORS error messages
+const (
+	ErrCORSOrigin = "origin is not allowed"
+	ErrCORSMethod = "method %s is not allowed for this route"
+	ErrCORSHeader = "request header %s is not allowed"
+)
+
+// Patch error messages
+const (
+	ErrUnsupportedPatch   = "PATCH requires application/merge-patch+json or application/json-patch+json"
+	ErrInvalidPatchResult = "patched book is invalid"
+)
+
+// Pagination error messages
+const (
+	ErrInvalidLimit     = "limit must be a number between 1 and 100"
+	ErrInvalidOffset    = "offset must be a non-negative number"
+	ErrInvalidCursor    = "cursor is invalid or expired"
+	ErrCursorWithOffset = "cursor and offset cannot be combined"
+)
+
+// Filter error messages
+const (
+	ErrInvalidStatusFilter = "status must be one of to-read, reading, read"
+	ErrInvalidYearRange    = "yearFrom must not be greater than yearTo"
+)
+
+// Batch error messages
+const (
+	ErrInvalidAtomicFlag = "atomic must be true or false"
+	ErrEmptyBatch        = "batch must contain at least one operation"
+	ErrBatchTooLarge     = "batch contains too many operations"
+	ErrInvalidBatchOp    = "op must be one of create, update, delete"
+)
+
+// Import error messages
+const (
+	ErrInvalidDryRunFlag    = "dryRun must be true or false"
+	ErrUnsupportedImport    = "import requires text/csv or application/json"
+	ErrImportTooLarge       = "import is too large (max 5000 rows, 10 MB)"
+	ErrEmptyImport          = "import contains no books"
+	ErrInvalidCSV           = "invalid CSV"
+	ErrInvalidColumnMapping = "map must look like Column:field with field one of title, author, isbn, year, description, status"
+	ErrDuplicateColumn      = "more than one column maps to"
+	ErrMissingColumn        = "CSV header is missing a column for"
+	ErrDuplicateInLibrary   = "duplicate of a book already in the library"
+	ErrDuplicateInImport    = "duplicate of row"
+)
+
+// Audit error messages
+const (
+	ErrFetchingAudit      = "error fetching audit log"
+	ErrInvalidAuditAction = "action must be one of create, update, delete, restore, purge"
+)
+
+// URL processing error messages
+const (
+	ErrInvalidURL = "url could not be parsed"
+)
+
+// Duplicate detection error messages
+const (
+	ErrInvalidForceFlag = "force must be true or false"
+	ErrInvalidThreshold = "threshold must be a number greater than 0 and at most 1"
+)
+
+// Export error messages
+const (
+	ErrInvalidExportFormat = "export format must be one of ndjson, csv, dc, marcxml"
+)
+
+// Sorting error messages
+const (
+	ErrInvalidSortField = "unknown sort field"
+	ErrInvalidOrderDir  = "orderDir must be asc or desc"
+)
+
+// Field validation messages, formatted with the field name and the rule
+// parameter
+const (
+	ErrFieldRequired  = "%s is required"
+	ErrFieldMin       = "%s must be at least %d"
+	ErrFieldMax       = "%s must be at most %d"
+	ErrFieldMinLength = "%s must be at least %d characters long"
+	ErrFieldMaxLength = "%s must be at most %d characters long"
+	ErrFieldOneOf     = "%s must be one of: %s"
+	ErrFieldURL       = "%s must be an absolute URL"
+	ErrFieldISBN      = "%s must be a valid ISBN-10 or ISBN-13"
+	ErrFieldNotFuture = "%s must not be in the future"
+	ErrFieldNotBefore = "%s must not be before %s"
+)
+
 // Validation error messages
 const (
-	ErrInvalidTitle       = "title is required and must not be empty"
-	ErrInvalidAuthor      = "author is required and must not be empty"
 	ErrInvalidYear        = "year must be a valid positive number"
 	ErrInvalidDescription = "description must not be empty"
 	ErrInvalidID          = "invalid ID format"
+	ErrInvalidISBN        = "isbn must be a valid ISBN-10 or ISBN-13"
 )
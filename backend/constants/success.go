@@ -2,9 +2,22 @@ package constants
 
 // Success messages
 const (
-	MsgBookCreated  = "book created successfully"
-	MsgBookUpdated  = "book updated successfully"
-	MsgBookDeleted  = "book deleted successfully"
-	MsgDatabaseInit = "database initialized successfully"
-	MsgBookFetched  = "book fetched successfully"
+	MsgBookCreated       = "book created successfully"
+	MsgBookUpdated       = "book updated successfully"
+	MsgBookDeleted       = "book deleted successfully"
+	MsgDatabaseInit      = "database initialized successfully"
+	MsgBookFetched       = "book fetched successfully"
+	MsgBatchProcessed    = "batch processed"
+	MsgBooksImported     = "import processed"
+	MsgBookRestored      = "book restored successfully"
+	MsgTrashFetched      = "trash fetched successfully"
+	MsgHistoryFetched    = "history fetched successfully"
+	MsgAuditFetched      = "audit log fetched successfully"
+	MsgDuplicatesFetched = "duplicates fetched successfully"
+	MsgUserFetched       = "user fetched successfully"
+	MsgShelfFetched      = "shelf fetched successfully"
+	MsgShelfBookFetched  = "shelf book fetched successfully"
+	MsgShelfBookAdded    = "book added to shelf"
+	MsgShelfBookUpdated  = "shelf book updated successfully"
+	MsgShelfBookRemoved  = "book removed from shelf"
 )
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"book-library-backend/models"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context whose store writes are audited as actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or AnonymousActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return models.AnonymousActor
+}
+
+// newAuditEntry describes the change from prev to next; prev is nil for
+// creates and the changes are empty for purges.
+func newAuditEntry(ctx context.Context, action string, prev, next *models.Book) *models.AuditEntry {
+	entry := &models.AuditEntry{
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		Timestamp: time.Now().UTC(),
+		Changes:   diffBooks(prev, next),
+	}
+	if next != nil {
+		entry.BookID = next.ID
+	} else if prev != nil {
+		entry.BookID = prev.ID
+	}
+	return entry
+}
+
+// diffBooks lists the user-visible fields that differ between prev and next
+func diffBooks(prev, next *models.Book) []models.FieldChange {
+	changes := make([]models.FieldChange, 0)
+	if next == nil {
+		return changes
+	}
+
+	var before models.Book
+	if prev != nil {
+		before = *prev
+	}
+	add := func(field string, old, new interface{}, changed bool) {
+		if prev == nil {
+			// Creates list every field that was set
+			if new == "" {
+				return
+			}
+			old = nil
+		} else if !changed {
+			return
+		}
+		changes = append(changes, models.FieldChange{Field: field, Old: old, New: new})
+	}
+
+	add("title", before.Title, next.Title, before.Title != next.Title)
+	add("author", before.Author, next.Author, before.Author != next.Author)
+	add("isbn", before.ISBN, next.ISBN, before.ISBN != next.ISBN)
+	add("year", before.Year, next.Year, before.Year != next.Year)
+	add("description", before.Description, next.Description, before.Description != next.Description)
+	add("status", before.Status, next.Status, before.Status != next.Status)
+	if prev != nil {
+		add("deleted_at", timeOrNil(before.DeletedAt), timeOrNil(next.DeletedAt),
+			(before.DeletedAt == nil) != (next.DeletedAt == nil))
+	}
+	return changes
+}
+
+func timeOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"book-library-backend/models"
+)
+
+// BatchResult is the outcome of one operation applied by ApplyBatch. Book is
+// nil for deletes and failed operations.
+type BatchResult struct {
+	Book *models.Book
+	Err  error
+}
+
+// abortBatch marks every operation except the failed one as rolled back
+func abortBatch(results []BatchResult, failed int) []BatchResult {
+	for i := range results {
+		if i != failed {
+			results[i] = BatchResult{Err: ErrBatchRolledBack}
+		}
+	}
+	return results
+}
+
+func unknownBatchOp(op string) error {
+	return fmt.Errorf("unknown batch operation %q", op)
+}
+
+// ApplyBatch runs ops in order while holding the write lock. In atomic mode
+// the first failure restores every book touched so far.
+func (db *InMemoryDB) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]BatchResult, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	// undo records the book stored under id before an operation touched it
+	type undo struct {
+		id   int
+		prev *models.Book
+	}
+	var undoLog []undo
+	nextID := db.nextID
+	auditLen := len(db.audit)
+
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		prev := db.books[op.ID]
+
+		var book *models.Book
+		var err error
+		switch op.Op {
+		case models.BatchCreate:
+			book, err = db.createLocked(ctx, op.Book)
+			prev = nil
+		case models.BatchUpdate:
+			book, err = db.updateLocked(ctx, op.ID, models.UpdateBookRequest(op.Book), op.Version)
+		case models.BatchDelete:
+			err = db.deleteLocked(ctx, op.ID, op.Version)
+		default:
+			err = unknownBatchOp(op.Op)
+		}
+		results[i] = BatchResult{Book: book, Err: err}
+
+		if err != nil {
+			if !atomic {
+				continue
+			}
+			for j := len(undoLog) - 1; j >= 0; j-- {
+				u := undoLog[j]
+				if u.prev == nil {
+					db.removeLocked(u.id)
+				} else {
+					db.putLocked(u.prev)
+				}
+			}
+			db.nextID = nextID
+			db.audit = db.audit[:auditLen]
+			return abortBatch(results, i), nil
+		}
+
+		id := op.ID
+		if book != nil {
+			id = book.ID
+		}
+		undoLog = append(undoLog, undo{id: id, prev: prev})
+	}
+
+	return results, nil
+}
+
+// ApplyBatch runs ops in order. In atomic mode they share one transaction
+// that is rolled back on the first failure; otherwise each runs in its own.
+func (s *SQLStore) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]BatchResult, error) {
+	results := make([]BatchResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			err := s.withTx(ctx, func(tx *sql.Tx) error {
+				results[i] = applyBatchOp(ctx, tx, op)
+				return results[i].Err
+			})
+			if err != nil && results[i].Err == nil {
+				results[i] = BatchResult{Err: err}
+			}
+			s.indexBatchResult(op, results[i])
+		}
+		return results, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i, op := range ops {
+		results[i] = applyBatchOp(ctx, tx, op)
+		if results[i].Err != nil {
+			return abortBatch(results, i), nil
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		s.indexBatchResult(op, results[i])
+	}
+
+	return results, nil
+}
+
+// applyBatchOp runs a single batch operation with q
+func applyBatchOp(ctx context.Context, q sqlExecutor, op models.BatchOperation) BatchResult {
+	var book *models.Book
+	var err error
+	switch op.Op {
+	case models.BatchCreate:
+		book, err = createBook(ctx, q, op.Book)
+	case models.BatchUpdate:
+		book, err = updateBook(ctx, q, op.ID, models.UpdateBookRequest(op.Book), op.Version)
+	case models.BatchDelete:
+		err = deleteBook(ctx, q, op.ID, op.Version)
+	default:
+		err = unknownBatchOp(op.Op)
+	}
+	return BatchResult{Book: book, Err: err}
+}
+
+// indexBatchResult mirrors a successful batch write into the search index
+func (s *SQLStore) indexBatchResult(op models.BatchOperation, result BatchResult) {
+	if result.Err != nil {
+		return
+	}
+	if op.Op == models.BatchDelete {
+		s.index.Remove(op.ID)
+		return
+	}
+	s.index.Put(result.Book.ID, searchDocument(result.Book))
+}
@@ -1,101 +1,162 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/search"
+	"book-library-backend/utils"
+
+	"github.com/mattn/go-sqlite3"
 )
 
-//! For simplicity and demonstration purpose, an in-memory SQLite database is used. Based on the size of application it may be necessary to switch
+//! SQLite is used as the persistent backend. Based on the size of application it may be necessary to switch
 //! to a more robust solution like PostgreSQL or MySQL. Please consider it before running stress tests.
 
-var DB *sql.DB
+// sqliteDriver is the sqlite3 driver with the SQL functions used by the
+// queries of this package registered on every connection
+const sqliteDriver = "sqlite3_library"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// fold lowercases like strings.ToLower; SQLite's lower() only
+			// handles ASCII, which would sort and filter unlike InMemoryDB
+			return conn.RegisterFunc("fold", strings.ToLower, true)
+		},
+	})
+}
+
+// SQLStore is a BookStore backed by a SQLite database file. The full-text
+// index is kept in memory, rebuilt at open and updated on every write.
+type SQLStore struct {
+	db    *sql.DB
+	index *search.Index
+}
+
+var _ BookStore = (*SQLStore)(nil)
+
+// OpenSQLStore opens (or creates) the SQLite database at path, applies
+// pending schema migrations and seeds sample data when the library is empty.
+func OpenSQLStore(path string) (*SQLStore, error) {
+	db, err := openSQLite(fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", path))
+	if err != nil {
+		return nil, err
+	}
 
-func InitDB() error {
-	var err error
+	store := &SQLStore{db: db, index: search.NewIndex()}
 
-	DB, err = sql.Open("sqlite3", "file::memory:?cache=shared")
+	// Migrate schema
+	applied, err := Migrate(context.Background(), db, false)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %v", err)
+		db.Close()
+		return nil, err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %s", m.Name)
 	}
 
-	// Test the connection
-	if err = DB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %v", err)
+	// Compute the duplicate keys of rows written before they existed
+	if err = store.backfillTitleKeys(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to backfill title keys: %v", err)
 	}
 
-	// Create tables
-	if err = createTables(); err != nil {
-		return fmt.Errorf("failed to create tables: %v", err)
+	// Build the search index from existing rows
+	if err = store.rebuildIndex(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to build search index: %v", err)
 	}
 
 	// Insert sample data
-	if err = insertSampleData(); err != nil {
+	if err = store.insertSampleData(); err != nil {
 		log.Printf("Warning: failed to insert sample data: %v", err)
 	}
 
-	log.Println("Database initialized successfully with sample data")
-	return nil
+	log.Println(constants.MsgDatabaseInit + " at " + path)
+	return store, nil
 }
 
-// createTables creates the necessary tables
-func createTables() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS books (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT NOT NULL,
-		author TEXT NOT NULL,
-		year INTEGER NOT NULL,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TRIGGER IF NOT EXISTS update_books_updated_at 
-		AFTER UPDATE ON books
-		BEGIN
-			UPDATE books SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
-		END;
-	`
-
-	_, err := DB.Exec(query)
-	return err
+// PlanSQLMigrations reports the migrations OpenSQLStore would apply to the
+// database at path without changing its schema. The database is opened
+// read-only and a missing file is reported as needing every migration
+// rather than created.
+func PlanSQLMigrations(path string) ([]Migration, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return loadMigrations()
+	}
+
+	db, err := openSQLite(fmt.Sprintf("file:%s?mode=ro&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return Migrate(context.Background(), db, true)
+}
+
+func openSQLite(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	// SQLite serialises writers anyway; a single connection avoids SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	// Test the connection
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	return db, nil
 }
 
-// Sample Data
-func insertSampleData() error {
-	sampleBooks := []struct {
-		title       string
-		author      string
-		year        int
-		description string
-	}{
+// Sample Data, only inserted into an empty library so restarts keep user data
+func (s *SQLStore) insertSampleData() error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM books").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	sampleBooks := []models.CreateBookRequest{
 		{
-			title:       "The Go Programming Language",
-			author:      "Alan Donovan and Brian Kernighan",
-			year:        2015,
-			description: "The authoritative resource to writing clear and idiomatic Go to solve real-world problems.",
+			Title:       "The Go Programming Language",
+			Author:      "Alan Donovan and Brian Kernighan",
+			Year:        2015,
+			Description: "The authoritative resource to writing clear and idiomatic Go to solve real-world problems.",
+			Status:      "read",
 		},
 		{
-			title:       "Clean Code",
-			author:      "Robert C. Martin",
-			year:        2008,
-			description: "A handbook of agile software craftsmanship that presents a revolutionary paradigm with practical advice.",
+			Title:       "Clean Code",
+			Author:      "Robert C. Martin",
+			Year:        2008,
+			Description: "A handbook of agile software craftsmanship that presents a revolutionary paradigm with practical advice.",
+			Status:      "reading",
 		},
 		{
-			title:       "Design Patterns",
-			author:      "Gang of Four",
-			year:        1994,
-			description: "Elements of Reusable Object-Oriented Software - the foundational text on software design patterns.",
+			Title:       "Design Patterns",
+			Author:      "Gang of Four",
+			Year:        1994,
+			Description: "Elements of Reusable Object-Oriented Software - the foundational text on software design patterns.",
+			Status:      "to-read",
 		},
 	}
 
 	for _, book := range sampleBooks {
-		_, err := DB.Exec(
-			"INSERT INTO books (title, author, year, description) VALUES (?, ?, ?, ?)",
-			book.title, book.author, book.year, book.description,
-		)
-		if err != nil {
+		if _, err := s.Create(context.Background(), book); err != nil {
 			return err
 		}
 	}
@@ -103,9 +164,498 @@ func insertSampleData() error {
 	return nil
 }
 
-func CloseDB() error {
-	if DB != nil {
-		return DB.Close()
+func (s *SQLStore) rebuildIndex() error {
+	books, err := s.List(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, book := range books {
+		s.index.Put(book.ID, searchDocument(book))
 	}
 	return nil
 }
+
+const bookColumns = "id, title, author, isbn, year, description, status, version, created_at, updated_at, deleted_at"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBook(row rowScanner) (*models.Book, error) {
+	book := &models.Book{}
+	var isbn sql.NullString
+	var deletedAt sql.NullTime
+	err := row.Scan(&book.ID, &book.Title, &book.Author, &isbn, &book.Year,
+		&book.Description, &book.Status, &book.Version, &book.CreatedAt, &book.UpdatedAt, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	book.ISBN = isbn.String
+	if deletedAt.Valid {
+		book.DeletedAt = &deletedAt.Time
+	}
+	return book, nil
+}
+
+func (s *SQLStore) List(ctx context.Context) ([]*models.Book, error) {
+	return s.queryBooks(ctx, "SELECT "+bookColumns+" FROM books WHERE deleted_at IS NULL ORDER BY id")
+}
+
+func (s *SQLStore) queryBooks(ctx context.Context, query string, args ...interface{}) ([]*models.Book, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make([]*models.Book, 0)
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+
+	return books, rows.Err()
+}
+
+// walkBatchSize is how many books Walk reads per query
+const walkBatchSize = 500
+
+// Walk reads the books in batches keyed by ID and calls fn between queries,
+// so the single connection is never held while fn runs (an export writing
+// to a slow client, say) and every other store call can proceed.
+func (s *SQLStore) Walk(ctx context.Context, fn func(*models.Book) error) error {
+	lastID := 0
+	for {
+		books, err := s.queryBooks(ctx,
+			"SELECT "+bookColumns+" FROM books WHERE deleted_at IS NULL AND id > ? ORDER BY id LIMIT ?",
+			lastID, walkBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, book := range books {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		if len(books) < walkBatchSize {
+			return nil
+		}
+		lastID = books[len(books)-1].ID
+	}
+}
+
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx so that the same
+// helpers serve plain reads and transactional writes.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (s *SQLStore) Get(ctx context.Context, id int) (*models.Book, error) {
+	return getBook(ctx, s.db, id)
+}
+
+func (s *SQLStore) FindByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	return findBook(ctx, s.db, "isbn = ? AND deleted_at IS NULL", isbn)
+}
+
+// withTx runs fn in a transaction committed only when fn succeeds, so that
+// a write and its audit entry are stored together.
+func (s *SQLStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) Create(ctx context.Context, req models.CreateBookRequest) (*models.Book, error) {
+	var book *models.Book
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		book, err = createBook(ctx, tx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.index.Put(book.ID, searchDocument(book))
+	return book, nil
+}
+
+func (s *SQLStore) Update(ctx context.Context, id int, req models.UpdateBookRequest, version int) (*models.Book, error) {
+	var book *models.Book
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		book, err = updateBook(ctx, tx, id, req, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.index.Put(book.ID, searchDocument(book))
+	return book, nil
+}
+
+func (s *SQLStore) Delete(ctx context.Context, id int, version int) error {
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		return deleteBook(ctx, tx, id, version)
+	})
+	if err != nil {
+		return err
+	}
+	s.index.Remove(id)
+	return nil
+}
+
+func (s *SQLStore) Exists(ctx context.Context, id int) (bool, error) {
+	return bookExists(ctx, s.db, id)
+}
+
+func getBook(ctx context.Context, q sqlExecutor, id int) (*models.Book, error) {
+	return findBook(ctx, q, "id = ? AND deleted_at IS NULL", id)
+}
+
+// findBook returns the single book matching where, or ErrBookNotFound
+func findBook(ctx context.Context, q sqlExecutor, where string, args ...interface{}) (*models.Book, error) {
+	row := q.QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books WHERE "+where, args...)
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBookNotFound
+	}
+	return book, err
+}
+
+func createBook(ctx context.Context, q sqlExecutor, req models.CreateBookRequest) (*models.Book, error) {
+	isbn, err := claimISBN(ctx, q, req.ISBN, 0)
+	if err != nil {
+		return nil, err
+	}
+	key := DuplicateKey(req.Title, req.Author)
+	if rejectsDuplicates(ctx) {
+		owner, err := duplicateOwner(ctx, q, key)
+		if err != nil {
+			return nil, err
+		}
+		if owner != 0 {
+			return nil, &DuplicateBookError{ExistingID: owner}
+		}
+	}
+
+	now := time.Now().UTC()
+	result, err := q.ExecContext(ctx,
+		"INSERT INTO books (title, author, title_key, isbn, year, description, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		req.Title, req.Author, key, isbn, req.Year, req.Description, catalogStatus(req.Status, models.DefaultCatalogStatus), now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := getBook(ctx, q, int(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return book, insertAuditEntry(ctx, q, newAuditEntry(ctx, models.AuditCreate, nil, book))
+}
+
+func updateBook(ctx context.Context, q sqlExecutor, id int, req models.UpdateBookRequest, version int) (*models.Book, error) {
+	prev, err := getBook(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	isbn, err := claimISBN(ctx, q, req.ISBN, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := q.ExecContext(ctx,
+		"UPDATE books SET title = ?, author = ?, title_key = ?, isbn = COALESCE(NULLIF(?, ''), isbn), year = ?, description = ?, status = COALESCE(NULLIF(?, ''), status), version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)",
+		req.Title, req.Author, DuplicateKey(req.Title, req.Author), isbn, req.Year, req.Description, req.Status, time.Now().UTC(), id, version, version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAffected(ctx, q, result, id); err != nil {
+		return nil, err
+	}
+
+	book, err := getBook(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return book, insertAuditEntry(ctx, q, newAuditEntry(ctx, models.AuditUpdate, prev, book))
+}
+
+func deleteBook(ctx context.Context, q sqlExecutor, id int, version int) error {
+	prev, err := getBook(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	result, err := q.ExecContext(ctx,
+		"UPDATE books SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)",
+		now, id, version, version,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAffected(ctx, q, result, id); err != nil {
+		return err
+	}
+
+	book, err := findBook(ctx, q, "id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return insertAuditEntry(ctx, q, newAuditEntry(ctx, models.AuditDelete, prev, book))
+}
+
+// claimISBN normalizes isbn and checks that no book other than id (trashed
+// ones included) already has it. The result is NULL for books without ISBN.
+func claimISBN(ctx context.Context, q sqlExecutor, isbn string, id int) (interface{}, error) {
+	isbn, err := utils.NormalizeISBN(isbn)
+	if err != nil || isbn == "" {
+		return nil, err
+	}
+
+	var taken bool
+	err = q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM books WHERE isbn = ? AND id != ?)", isbn, id).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrDuplicateISBN
+	}
+	return isbn, nil
+}
+
+func bookExists(ctx context.Context, q sqlExecutor, id int) (bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM books WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists)
+	return exists, err
+}
+
+// checkAffected turns a conditional write that touched no row into either
+// ErrBookNotFound or ErrVersionMismatch.
+func checkAffected(ctx context.Context, q sqlExecutor, result sql.Result, id int) error {
+	n, err := result.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+
+	exists, err := bookExists(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrBookNotFound
+}
+
+func (s *SQLStore) Trash(ctx context.Context) ([]*models.Book, error) {
+	return s.queryBooks(ctx, "SELECT "+bookColumns+" FROM books WHERE deleted_at IS NOT NULL ORDER BY id")
+}
+
+func (s *SQLStore) Restore(ctx context.Context, id int) (*models.Book, error) {
+	var book *models.Book
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		prev, err := findBook(ctx, tx, "id = ? AND deleted_at IS NOT NULL", id)
+		if err != nil {
+			if !errors.Is(err, ErrBookNotFound) {
+				return err
+			}
+			exists, err := bookExists(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ErrBookNotInTrash
+			}
+			return ErrBookNotFound
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE books SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ?",
+			time.Now().UTC(), id,
+		)
+		if err != nil {
+			return err
+		}
+
+		if book, err = getBook(ctx, tx, id); err != nil {
+			return err
+		}
+		return insertAuditEntry(ctx, tx, newAuditEntry(ctx, models.AuditRestore, prev, book))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.index.Put(book.ID, searchDocument(book))
+	return book, nil
+}
+
+func (s *SQLStore) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"SELECT "+bookColumns+" FROM books WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff.UTC())
+		if err != nil {
+			return err
+		}
+		var expired []*models.Book
+		for rows.Next() {
+			book, err := scanBook(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			expired = append(expired, book)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, book := range expired {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM books WHERE id = ?", book.ID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "DELETE FROM user_books WHERE book_id = ?", book.ID); err != nil {
+				return err
+			}
+			if err := insertAuditEntry(ctx, tx, newAuditEntry(ctx, models.AuditPurge, book, nil)); err != nil {
+				return err
+			}
+		}
+		purged = len(expired)
+		return nil
+	})
+	return purged, err
+}
+
+func insertAuditEntry(ctx context.Context, q sqlExecutor, entry *models.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO audit_log (book_id, action, actor, created_at, changes) VALUES (?, ?, ?, ?, ?)",
+		entry.BookID, entry.Action, entry.Actor, entry.Timestamp, string(changes),
+	)
+	return err
+}
+
+func (s *SQLStore) AuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	var where []string
+	var args []interface{}
+	if filter.BookID != 0 {
+		where = append(where, "book_id = ?")
+		args = append(args, filter.BookID)
+	}
+	if filter.Actor != "" {
+		where = append(where, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	query := "SELECT id, book_id, action, actor, created_at, changes FROM audit_log"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditEntry, 0)
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var changes string
+		if err := rows.Scan(&entry.ID, &entry.BookID, &entry.Action, &entry.Actor, &entry.Timestamp, &changes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// searchLookupBatch caps how many hits Search looks up per query, keeping
+// the IN list well under SQLite's limit on bound parameters
+const searchLookupBatch = 500
+
+func (s *SQLStore) Search(ctx context.Context, query string) ([]*models.ScoredBook, error) {
+	hits := s.index.Search(query)
+	if len(hits) == 0 {
+		return []*models.ScoredBook{}, nil
+	}
+
+	byID := make(map[int]*models.Book, len(hits))
+	for start := 0; start < len(hits); start += searchLookupBatch {
+		batch := hits[start:min(start+searchLookupBatch, len(hits))]
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for i, hit := range batch {
+			placeholders[i] = "?"
+			args[i] = hit.ID
+		}
+
+		books, err := s.queryBooks(ctx,
+			"SELECT "+bookColumns+" FROM books WHERE deleted_at IS NULL AND id IN ("+strings.Join(placeholders, ", ")+")", args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, book := range books {
+			byID[book.ID] = book
+		}
+	}
+
+	results := make([]*models.ScoredBook, 0, len(hits))
+	for _, hit := range hits {
+		if book, ok := byID[hit.ID]; ok {
+			results = append(results, &models.ScoredBook{Book: book, Score: hit.Score})
+		}
+	}
+	return results, nil
+}
+
+// Close releases the underlying database handle.
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"book-library-backend/search"
+)
+
+// DuplicateKey identifies a book by its normalized title and author. Create
+// compares it when the context comes from RejectDuplicates.
+func DuplicateKey(title, author string) string {
+	return search.Normalize(title) + "\x00" + search.Normalize(author)
+}
+
+type rejectDuplicatesKey struct{}
+
+// RejectDuplicates returns a context whose Create calls fail with a
+// *DuplicateBookError when a book outside the trash already has the same
+// DuplicateKey. The check runs under the same lock or transaction as the
+// insert, so concurrent creates cannot both get through.
+func RejectDuplicates(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rejectDuplicatesKey{}, true)
+}
+
+func rejectsDuplicates(ctx context.Context) bool {
+	reject, _ := ctx.Value(rejectDuplicatesKey{}).(bool)
+	return reject
+}
+
+// DuplicateBookError reports the book a create would have duplicated. It
+// matches ErrDuplicateBook with errors.Is.
+type DuplicateBookError struct {
+	ExistingID int
+}
+
+func (e *DuplicateBookError) Error() string {
+	return ErrDuplicateBook.Error()
+}
+
+func (e *DuplicateBookError) Is(target error) bool {
+	return target == ErrDuplicateBook
+}
+
+// duplicateOwnerLocked returns the lowest ID of the books outside the trash
+// whose DuplicateKey is key, or 0; db.mutex must be held
+func (db *InMemoryDB) duplicateOwnerLocked(key string) int {
+	owner := 0
+	for id := range db.titleKeys[key] {
+		if owner == 0 || id < owner {
+			owner = id
+		}
+	}
+	return owner
+}
+
+// duplicateOwner returns the lowest ID of the books outside the trash whose
+// title_key is key, or 0
+func duplicateOwner(ctx context.Context, q sqlExecutor, key string) (int, error) {
+	var id int
+	err := q.QueryRowContext(ctx,
+		"SELECT id FROM books WHERE title_key = ? AND deleted_at IS NULL ORDER BY id LIMIT 1", key).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// backfillTitleKeys fills title_key for the rows written before the column
+// existed
+func (s *SQLStore) backfillTitleKeys(ctx context.Context) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "SELECT id, title, author FROM books WHERE title_key IS NULL")
+		if err != nil {
+			return err
+		}
+		keys := make(map[int]string)
+		for rows.Next() {
+			var id int
+			var title, author string
+			if err := rows.Scan(&id, &title, &author); err != nil {
+				rows.Close()
+				return err
+			}
+			keys[id] = DuplicateKey(title, author)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, key := range keys {
+			if _, err := tx.ExecContext(ctx, "UPDATE books SET title_key = ? WHERE id = ?", key, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
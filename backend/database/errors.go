@@ -0,0 +1,21 @@
+package database
+
+import (
+	"errors"
+
+	"book-library-backend/constants"
+)
+
+// Errors returned by every BookStore. Callers match them with errors.Is; the
+// messages are the user-facing constants so they can be reported as is.
+var (
+	ErrBookNotFound    = errors.New(constants.ErrBookNotFound)
+	ErrVersionMismatch = errors.New(constants.ErrVersionMismatch)
+	ErrBookNotInTrash  = errors.New(constants.ErrBookNotInTrash)
+	ErrDuplicateISBN   = errors.New(constants.ErrDuplicateISBN)
+	ErrDuplicateBook   = errors.New(constants.ErrBookAlreadyExists)
+	ErrBatchRolledBack = errors.New(constants.ErrBatchRolledBack)
+
+	ErrUserNotFound     = errors.New(constants.ErrUserNotFound)
+	ErrUserBookNotFound = errors.New(constants.ErrUserBookNotFound)
+)
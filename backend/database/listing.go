@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"book-library-backend/models"
+)
+
+// sqlSortTerm is one ORDER BY term: the column expression, the placeholder a
+// seek value is compared through and how to read that value off a book
+type sqlSortTerm struct {
+	column string
+	param  string
+	value  func(book *models.Book) interface{}
+	desc   bool
+}
+
+// sqlSortTerms holds the terms of every sort field, matching the order of
+// models.CompareBooks: titles and authors case-insensitively, then as is.
+var sqlSortTerms = map[string][]sqlSortTerm{
+	"id": {{column: "id", param: "?", value: func(b *models.Book) interface{} { return b.ID }}},
+	"title": {
+		{column: "fold(title)", param: "fold(?)", value: func(b *models.Book) interface{} { return b.Title }},
+		{column: "title", param: "?", value: func(b *models.Book) interface{} { return b.Title }},
+	},
+	"author": {
+		{column: "fold(author)", param: "fold(?)", value: func(b *models.Book) interface{} { return b.Author }},
+		{column: "author", param: "?", value: func(b *models.Book) interface{} { return b.Author }},
+	},
+	"year":       {{column: "year", param: "?", value: func(b *models.Book) interface{} { return b.Year }}},
+	"status":     {{column: "status", param: "?", value: func(b *models.Book) interface{} { return b.Status }}},
+	"created_at": {{column: "created_at", param: "?", value: func(b *models.Book) interface{} { return b.CreatedAt.UTC() }}},
+	"updated_at": {{column: "updated_at", param: "?", value: func(b *models.Book) interface{} { return b.UpdatedAt.UTC() }}},
+}
+
+// ListPage filters, sorts and pages the books in SQL so that a page reads
+// at most query.Limit rows. Cursor pages seek with the sort key of the cursor
+// book instead of skipping rows.
+func (s *SQLStore) ListPage(ctx context.Context, query models.BookQuery) (*models.BookPage, error) {
+	where, args := bookFilterSQL(query.Filter, models.FacetNone)
+	page := &models.BookPage{}
+
+	total, err := s.countBooks(ctx, where, args)
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+	if page.Facets, err = s.bookFacets(ctx, query.Filter); err != nil {
+		return nil, err
+	}
+
+	var terms []sqlSortTerm
+	for _, key := range query.Sort {
+		for _, term := range sqlSortTerms[key.Field] {
+			term.desc = key.Desc
+			terms = append(terms, term)
+		}
+	}
+
+	// A Before page is read backwards from the seek book, then reversed
+	var seek string
+	var seekArgs []interface{}
+	if query.Seek != nil {
+		seek, seekArgs = seekSQL(terms, query.Seek, !query.Before)
+	}
+	pageWhere, pageArgs := where, append([]interface{}{}, args...)
+	if seek != "" {
+		pageWhere += " AND " + seek
+		pageArgs = append(pageArgs, seekArgs...)
+	}
+	order := make([]string, len(terms))
+	for i, term := range terms {
+		dir := " ASC"
+		if term.desc != (query.Seek != nil && query.Before) {
+			dir = " DESC"
+		}
+		order[i] = term.column + dir
+	}
+	sqlQuery := "SELECT " + bookColumns + " FROM books WHERE " + pageWhere + " ORDER BY " + strings.Join(order, ", ")
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT ?"
+		pageArgs = append(pageArgs, query.Limit)
+		if query.Seek == nil {
+			sqlQuery += " OFFSET ?"
+			pageArgs = append(pageArgs, query.Offset)
+		}
+	}
+	if page.Books, err = s.queryBooks(ctx, sqlQuery, pageArgs...); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case query.Seek == nil:
+		page.Offset = query.Offset
+		if page.Offset > total {
+			page.Offset = total
+		}
+	case query.Before:
+		for i, j := 0, len(page.Books)-1; i < j; i, j = i+1, j-1 {
+			page.Books[i], page.Books[j] = page.Books[j], page.Books[i]
+		}
+		before, err := s.countBooks(ctx, where+" AND "+seek, append(append([]interface{}{}, args...), seekArgs...))
+		if err != nil {
+			return nil, err
+		}
+		page.Offset = before - len(page.Books)
+	default:
+		after, err := s.countBooks(ctx, where+" AND "+seek, append(append([]interface{}{}, args...), seekArgs...))
+		if err != nil {
+			return nil, err
+		}
+		page.Offset = total - after
+	}
+
+	return page, nil
+}
+
+func (s *SQLStore) countBooks(ctx context.Context, where string, args []interface{}) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books WHERE "+where, args...).Scan(&count)
+	return count, err
+}
+
+// bookFacets counts the books per status and per decade, each facet under
+// every filter but its own, like models.CountFacets
+func (s *SQLStore) bookFacets(ctx context.Context, f models.BookFilter) (*models.Facets, error) {
+	facets := models.NewFacets()
+	groups := []struct {
+		facet  string
+		column string
+		counts map[string]int
+	}{
+		{models.FacetStatus, "status", facets.Status},
+		{models.FacetDecade, "(year / 10 * 10) || 's'", facets.Decade},
+	}
+
+	for _, group := range groups {
+		where, args := bookFilterSQL(f, group.facet)
+		rows, err := s.db.QueryContext(ctx,
+			"SELECT "+group.column+", COUNT(*) FROM books WHERE "+where+" GROUP BY 1", args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var value string
+			var count int
+			if err := rows.Scan(&value, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			group.counts[value] = count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return facets, nil
+}
+
+// bookFilterSQL translates f, leaving out the filter backing facet, into a
+// WHERE clause over the books that are not in the trash
+func bookFilterSQL(f models.BookFilter, facet string) (string, []interface{}) {
+	where := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if f.Title != "" {
+		where = append(where, "instr(fold(title), ?) > 0")
+		args = append(args, f.Title)
+	}
+	if f.Author != "" {
+		where = append(where, "instr(fold(author), ?) > 0")
+		args = append(args, f.Author)
+	}
+	if facet != models.FacetStatus && f.Statuses != nil {
+		statuses := make([]string, 0, len(f.Statuses))
+		for status := range f.Statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		placeholders := make([]string, len(statuses))
+		for i, status := range statuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		where = append(where, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if facet != models.FacetDecade {
+		if f.YearFrom != 0 {
+			where = append(where, "year >= ?")
+			args = append(args, f.YearFrom)
+		}
+		if f.YearTo != 0 {
+			where = append(where, "year <= ?")
+			args = append(args, f.YearTo)
+		}
+	}
+	if !f.CreatedAfter.IsZero() {
+		where = append(where, "created_at > ?")
+		args = append(args, f.CreatedAfter.UTC())
+	}
+	if !f.UpdatedSince.IsZero() {
+		where = append(where, "updated_at >= ?")
+		args = append(args, f.UpdatedSince.UTC())
+	}
+
+	return strings.Join(where, " AND "), args
+}
+
+// seekSQL is the condition selecting the rows sorted after book by terms, or
+// before it when after is false. Terms may mix directions, so instead of a
+// single row value comparison it expands to
+// (t1 > v1) OR (t1 = v1 AND t2 > v2) OR ...
+func seekSQL(terms []sqlSortTerm, book *models.Book, after bool) (string, []interface{}) {
+	var ors []string
+	var args []interface{}
+	for i, term := range terms {
+		ands := make([]string, 0, i+1)
+		for _, prev := range terms[:i] {
+			ands = append(ands, prev.column+" = "+prev.param)
+			args = append(args, prev.value(book))
+		}
+		op := " > "
+		if term.desc == after {
+			op = " < "
+		}
+		ands = append(ands, term.column+op+term.param)
+		args = append(args, term.value(book))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
@@ -1,28 +1,53 @@
 package database
 
 import (
-	"errors"
+	"context"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"book-library-backend/constants"
 	"book-library-backend/models"
+	"book-library-backend/search"
+	"book-library-backend/utils"
 )
 
+// InMemoryDB is a BookStore backed by a map guarded by a RWMutex.
 type InMemoryDB struct {
-	books  map[int]*models.Book
-	nextID int
-	mutex  sync.RWMutex
+	books map[int]*models.Book
+	index *search.Index
+	// titleKeys maps the DuplicateKey of the books outside the trash to
+	// their IDs
+	titleKeys map[string]map[int]bool
+	audit     []*models.AuditEntry
+	nextID    int
+	// users are keyed by username and shelves by user ID, then book ID
+	users      map[string]*models.User
+	shelves    map[int]map[int]*models.UserBook
+	nextUserID int
+	mutex      sync.RWMutex
 }
 
-var memDB *InMemoryDB
+var _ BookStore = (*InMemoryDB)(nil)
 
-func InitMemoryDB() error {
-	memDB = &InMemoryDB{
-		books:  make(map[int]*models.Book),
-		nextID: 1,
+// NewInMemoryDB returns an empty in-memory store.
+func NewInMemoryDB() *InMemoryDB {
+	return &InMemoryDB{
+		books:     make(map[int]*models.Book),
+		index:     search.NewIndex(),
+		titleKeys: make(map[string]map[int]bool),
+		nextID:    1,
+
+		users:      make(map[string]*models.User),
+		shelves:    make(map[int]map[int]*models.UserBook),
+		nextUserID: 1,
 	}
+}
+
+// InitMemoryDB returns an in-memory store seeded with sample data.
+func InitMemoryDB() (*InMemoryDB, error) {
+	db := NewInMemoryDB()
 
 	sampleBooks := []*models.Book{
 		{
@@ -32,6 +57,7 @@ func InitMemoryDB() error {
 			Year:        1960,
 			Description: "A classic novel exploring themes of racial injustice and moral growth in the American South.",
 			Status:      "read",
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -42,6 +68,7 @@ func InitMemoryDB() error {
 			Year:        1949,
 			Description: "A dystopian novel depicting a totalitarian regime where surveillance and propaganda control every aspect of life.",
 			Status:      "reading",
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -52,127 +79,402 @@ func InitMemoryDB() error {
 			Year:        1925,
 			Description: "A tragic story of love, wealth, and the American Dream set in the Roaring Twenties.",
 			Status:      "to-read",
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 	}
 
 	for _, book := range sampleBooks {
-		memDB.books[book.ID] = book
-		if book.ID >= memDB.nextID {
-			memDB.nextID = book.ID + 1
+		db.putLocked(book)
+		if book.ID >= db.nextID {
+			db.nextID = book.ID + 1
 		}
 	}
 
 	log.Println(constants.MsgDatabaseInit + " with sample data")
-	return nil
+	return db, nil
+}
+
+func (db *InMemoryDB) List(ctx context.Context) ([]*models.Book, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	return db.collectLocked(func(book *models.Book) bool { return book.DeletedAt == nil }), nil
 }
 
-func GetAllBooks() ([]*models.Book, error) {
-	if memDB == nil {
-		return nil, errors.New(constants.ErrDatabaseNotInitialized)
+func (db *InMemoryDB) ListPage(ctx context.Context, query models.BookQuery) (*models.BookPage, error) {
+	books, err := db.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*models.Book, 0, len(books))
+	for _, book := range books {
+		if query.Filter.Matches(book) {
+			matches = append(matches, book)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return models.CompareBooks(matches[i], matches[j], query.Sort) < 0 })
+
+	total := len(matches)
+	start, end := query.Offset, total
+	if query.Seek != nil {
+		// Position of the first book sorted after the seek book (or, for
+		// Before, of the seek book itself)
+		end = sort.Search(total, func(i int) bool {
+			c := models.CompareBooks(matches[i], query.Seek, query.Sort)
+			return c > 0 || (query.Before && c == 0)
+		})
+		start = end
+		if query.Before {
+			start = end - query.Limit
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	if start > total {
+		start = total
+	}
+	if query.Limit > 0 && (query.Seek == nil || !query.Before) {
+		end = start + query.Limit
+		if end > total {
+			end = total
+		}
 	}
 
-	memDB.mutex.RLock()
-	defer memDB.mutex.RUnlock()
+	return &models.BookPage{
+		Books:  matches[start:end],
+		Offset: start,
+		Total:  total,
+		Facets: models.CountFacets(books, query.Filter),
+	}, nil
+}
 
-	books := make([]*models.Book, 0, len(memDB.books))
-	for _, book := range memDB.books {
-		books = append(books, book)
+// collectLocked copies the books accepted by keep in ID order. Map iteration
+// order is random; listings are kept stable for pagination.
+func (db *InMemoryDB) collectLocked(keep func(*models.Book) bool) []*models.Book {
+	books := make([]*models.Book, 0, len(db.books))
+	for _, book := range db.books {
+		if keep(book) {
+			books = append(books, copyBook(book))
+		}
 	}
 
-	return books, nil
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books
 }
 
-func GetBookByID(id int) (*models.Book, error) {
-	if memDB == nil {
-		return nil, errors.New(constants.ErrDatabaseNotInitialized)
+func (db *InMemoryDB) Walk(ctx context.Context, fn func(*models.Book) error) error {
+	// Stored books are never mutated in place, so a snapshot of the pointers
+	// is enough to walk without holding the lock while fn runs.
+	db.mutex.RLock()
+	snapshot := make([]*models.Book, 0, len(db.books))
+	for _, book := range db.books {
+		if book.DeletedAt == nil {
+			snapshot = append(snapshot, book)
+		}
 	}
+	db.mutex.RUnlock()
 
-	memDB.mutex.RLock()
-	defer memDB.mutex.RUnlock()
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].ID < snapshot[j].ID })
+	for _, book := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(copyBook(book)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	book, exists := memDB.books[id]
-	if !exists {
-		return nil, errors.New(constants.ErrBookNotFound)
+func (db *InMemoryDB) Get(ctx context.Context, id int) (*models.Book, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	book, exists := db.books[id]
+	if !exists || book.DeletedAt != nil {
+		return nil, ErrBookNotFound
 	}
 
-	return book, nil
+	return copyBook(book), nil
 }
 
-func CreateBook(req models.CreateBookRequest) (*models.Book, error) {
-	if memDB == nil {
-		return nil, errors.New(constants.ErrDatabaseNotInitialized)
+func (db *InMemoryDB) FindByISBN(ctx context.Context, isbn string) (*models.Book, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	if book, exists := db.books[db.isbnOwnerLocked(isbn)]; exists && book.DeletedAt == nil {
+		return copyBook(book), nil
 	}
+	return nil, ErrBookNotFound
+}
+
+func (db *InMemoryDB) Create(ctx context.Context, req models.CreateBookRequest) (*models.Book, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	return db.createLocked(ctx, req)
+}
 
-	memDB.mutex.Lock()
-	defer memDB.mutex.Unlock()
+func (db *InMemoryDB) Update(ctx context.Context, id int, req models.UpdateBookRequest, version int) (*models.Book, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
 
+	return db.updateLocked(ctx, id, req, version)
+}
+
+func (db *InMemoryDB) Delete(ctx context.Context, id int, version int) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	return db.deleteLocked(ctx, id, version)
+}
+
+// createLocked, updateLocked and deleteLocked expect db.mutex to be held.
+// Stored books are replaced rather than mutated so that a batch can restore
+// the previous pointer on rollback.
+func (db *InMemoryDB) createLocked(ctx context.Context, req models.CreateBookRequest) (*models.Book, error) {
+	isbn, err := db.claimISBNLocked(req.ISBN, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rejectsDuplicates(ctx) {
+		if owner := db.duplicateOwnerLocked(DuplicateKey(req.Title, req.Author)); owner != 0 {
+			return nil, &DuplicateBookError{ExistingID: owner}
+		}
+	}
+
+	now := time.Now()
 	book := &models.Book{
-		ID:          memDB.nextID,
+		ID:          db.nextID,
 		Title:       req.Title,
 		Author:      req.Author,
+		ISBN:        isbn,
 		Year:        req.Year,
 		Description: req.Description,
-		Status:      req.Status,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Status:      catalogStatus(req.Status, models.DefaultCatalogStatus),
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
-	memDB.books[book.ID] = book
-	memDB.nextID++
+	db.putLocked(book)
+	db.nextID++
+	db.recordLocked(newAuditEntry(ctx, models.AuditCreate, nil, book))
 
-	return book, nil
+	return copyBook(book), nil
 }
 
-func UpdateBook(id int, req models.UpdateBookRequest) (*models.Book, error) {
-	if memDB == nil {
-		return nil, errors.New(constants.ErrDatabaseNotInitialized)
+func (db *InMemoryDB) updateLocked(ctx context.Context, id int, req models.UpdateBookRequest, version int) (*models.Book, error) {
+	current, exists := db.books[id]
+	if !exists || current.DeletedAt != nil {
+		return nil, ErrBookNotFound
 	}
-
-	memDB.mutex.Lock()
-	defer memDB.mutex.Unlock()
-
-	book, exists := memDB.books[id]
-	if !exists {
-		return nil, errors.New(constants.ErrBookNotFound)
+	if version != AnyVersion && current.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	isbn, err := db.claimISBNLocked(req.ISBN, id)
+	if err != nil {
+		return nil, err
+	}
+	// Updates that leave the ISBN out keep the current one
+	if isbn == "" {
+		isbn = current.ISBN
 	}
 
+	book := copyBook(current)
 	book.Title = req.Title
 	book.Author = req.Author
+	book.ISBN = isbn
 	book.Year = req.Year
 	book.Description = req.Description
-	book.Status = req.Status
+	book.Status = catalogStatus(req.Status, current.Status)
+	book.Version++
 	book.UpdatedAt = time.Now()
 
-	return book, nil
+	db.putLocked(book)
+	db.recordLocked(newAuditEntry(ctx, models.AuditUpdate, current, book))
+
+	return copyBook(book), nil
+}
+
+func (db *InMemoryDB) deleteLocked(ctx context.Context, id int, version int) error {
+	current, exists := db.books[id]
+	if !exists || current.DeletedAt != nil {
+		return ErrBookNotFound
+	}
+	if version != AnyVersion && current.Version != version {
+		return ErrVersionMismatch
+	}
+
+	now := time.Now()
+	book := copyBook(current)
+	book.DeletedAt = &now
+	book.Version++
+
+	db.putLocked(book)
+	db.recordLocked(newAuditEntry(ctx, models.AuditDelete, current, book))
+	return nil
+}
+
+// putLocked stores book under its ID and keeps the search index and the
+// duplicate keys in step with it; db.mutex must be held
+func (db *InMemoryDB) putLocked(book *models.Book) {
+	db.removeLocked(book.ID)
+	db.books[book.ID] = book
+	if book.DeletedAt != nil {
+		return
+	}
+	db.index.Put(book.ID, searchDocument(book))
+	key := DuplicateKey(book.Title, book.Author)
+	if db.titleKeys[key] == nil {
+		db.titleKeys[key] = make(map[int]bool)
+	}
+	db.titleKeys[key][book.ID] = true
+}
+
+// removeLocked drops the book stored under id along with its index entries;
+// db.mutex must be held
+func (db *InMemoryDB) removeLocked(id int) {
+	book, exists := db.books[id]
+	if !exists {
+		return
+	}
+	delete(db.books, id)
+	db.index.Remove(id)
+	key := DuplicateKey(book.Title, book.Author)
+	delete(db.titleKeys[key], id)
+	if len(db.titleKeys[key]) == 0 {
+		delete(db.titleKeys, key)
+	}
+}
+
+// claimISBNLocked normalizes isbn and checks that no book other than id
+// (trashed ones included) already has it; db.mutex must be held
+func (db *InMemoryDB) claimISBNLocked(isbn string, id int) (string, error) {
+	isbn, err := utils.NormalizeISBN(isbn)
+	if err != nil {
+		return "", err
+	}
+	if owner := db.isbnOwnerLocked(isbn); owner != 0 && owner != id {
+		return "", ErrDuplicateISBN
+	}
+	return isbn, nil
 }
 
-func DeleteBook(id int) error {
-	if memDB == nil {
-		return errors.New(constants.ErrDatabaseNotInitialized)
+// isbnOwnerLocked returns the ID of the book with isbn, or 0
+func (db *InMemoryDB) isbnOwnerLocked(isbn string) int {
+	if isbn == "" {
+		return 0
 	}
+	for id, book := range db.books {
+		if book.ISBN == isbn {
+			return id
+		}
+	}
+	return 0
+}
 
-	memDB.mutex.Lock()
-	defer memDB.mutex.Unlock()
+// recordLocked appends entry to the audit log; db.mutex must be held
+func (db *InMemoryDB) recordLocked(entry *models.AuditEntry) {
+	entry.ID = len(db.audit) + 1
+	db.audit = append(db.audit, entry)
+}
+
+func (db *InMemoryDB) Exists(ctx context.Context, id int) (bool, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
 
-	_, exists := memDB.books[id]
+	book, exists := db.books[id]
+	return exists && book.DeletedAt == nil, nil
+}
+
+func (db *InMemoryDB) Trash(ctx context.Context) ([]*models.Book, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	return db.collectLocked(func(book *models.Book) bool { return book.DeletedAt != nil }), nil
+}
+
+func (db *InMemoryDB) Restore(ctx context.Context, id int) (*models.Book, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	current, exists := db.books[id]
 	if !exists {
-		return errors.New(constants.ErrBookNotFound)
+		return nil, ErrBookNotFound
+	}
+	if current.DeletedAt == nil {
+		return nil, ErrBookNotInTrash
 	}
 
-	delete(memDB.books, id)
-	return nil
+	book := copyBook(current)
+	book.DeletedAt = nil
+	book.Version++
+	book.UpdatedAt = time.Now()
+
+	db.putLocked(book)
+	db.recordLocked(newAuditEntry(ctx, models.AuditRestore, current, book))
+
+	return copyBook(book), nil
 }
 
-func BookExists(id int) (bool, error) {
-	if memDB == nil {
-		return false, errors.New(constants.ErrDatabaseNotInitialized)
+func (db *InMemoryDB) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	purged := 0
+	for id, book := range db.books {
+		if book.DeletedAt != nil && book.DeletedAt.Before(cutoff) {
+			db.removeLocked(id)
+			for _, shelf := range db.shelves {
+				delete(shelf, id)
+			}
+			db.recordLocked(newAuditEntry(ctx, models.AuditPurge, book, nil))
+			purged++
+		}
 	}
+	return purged, nil
+}
 
-	memDB.mutex.RLock()
-	defer memDB.mutex.RUnlock()
+func (db *InMemoryDB) AuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
 
-	_, exists := memDB.books[id]
-	return exists, nil
+	entries := make([]*models.AuditEntry, 0)
+	for _, entry := range db.audit {
+		if filter.Matches(entry) {
+			c := *entry
+			entries = append(entries, &c)
+		}
+	}
+	return entries, nil
+}
+
+func (db *InMemoryDB) Search(ctx context.Context, query string) ([]*models.ScoredBook, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	hits := db.index.Search(query)
+	results := make([]*models.ScoredBook, 0, len(hits))
+	for _, hit := range hits {
+		if book, exists := db.books[hit.ID]; exists {
+			results = append(results, &models.ScoredBook{Book: copyBook(book), Score: hit.Score})
+		}
+	}
+
+	return results, nil
+}
+
+// copyBook returns a copy so callers never share the stored pointers.
+func copyBook(book *models.Book) *models.Book {
+	c := *book
+	if book.DeletedAt != nil {
+		deletedAt := *book.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	return &c
 }
@@ -0,0 +1,12 @@
+-- Initial books table. IF NOT EXISTS keeps databases created before
+-- migrations were introduced compatible.
+CREATE TABLE IF NOT EXISTS books (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	year INTEGER NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'to-read',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Indexes backing the listing filters on author and status.
+CREATE INDEX IF NOT EXISTS idx_books_author ON books (author);
+CREATE INDEX IF NOT EXISTS idx_books_status ON books (status);
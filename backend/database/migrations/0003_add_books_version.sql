@@ -0,0 +1,2 @@
+-- Per-book version counter for optimistic concurrency (ETag / If-Match).
+ALTER TABLE books ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
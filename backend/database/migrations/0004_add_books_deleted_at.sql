@@ -0,0 +1,3 @@
+-- Soft delete: trashed books keep their row until the retention purge.
+ALTER TABLE books ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
@@ -0,0 +1,12 @@
+-- Audit trail of book changes. Entries outlive purged books, so book_id is
+-- deliberately not a foreign key.
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	book_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	changes TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_book_id ON audit_log (book_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor);
@@ -0,0 +1,4 @@
+-- Normalized title and author (see DuplicateKey), filled by the application,
+-- so that creates can reject duplicates without scanning the catalog.
+ALTER TABLE books ADD COLUMN title_key TEXT;
+CREATE INDEX IF NOT EXISTS idx_books_title_key ON books (title_key);
@@ -0,0 +1,4 @@
+-- Optional ISBN, stored normalized to ISBN-13. Books without one keep NULL so
+-- the unique index only covers books that have an ISBN.
+ALTER TABLE books ADD COLUMN isbn TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_isbn ON books (isbn) WHERE isbn IS NOT NULL;
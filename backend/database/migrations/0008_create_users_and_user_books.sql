@@ -0,0 +1,21 @@
+-- Users own personal shelves of catalog books. Each shelf item keeps the
+-- user's own reading status, dates and notes; the catalog row is shared.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL UNIQUE,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_books (
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	book_id INTEGER NOT NULL REFERENCES books (id),
+	status TEXT NOT NULL,
+	started_at DATETIME,
+	finished_at DATETIME,
+	notes TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_books_book_id ON user_books (book_id);
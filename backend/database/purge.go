@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"book-library-backend/models"
+)
+
+// RunTrashPurge permanently removes books that have been in the trash longer
+// than retention, checking every interval until ctx is cancelled.
+func RunTrashPurge(ctx context.Context, store BookStore, retention, interval time.Duration) {
+	ctx = WithActor(ctx, models.SystemActor)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := store.Purge(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Warning: trash purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d book(s) from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
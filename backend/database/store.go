@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"book-library-backend/models"
+	"book-library-backend/search"
+)
+
+// BookStore is the persistence contract used by the HTTP handlers. Every
+// backend (in-memory, SQL, ...) implements it so that handlers never reach
+// into package-level state.
+type BookStore interface {
+	Get(ctx context.Context, id int) (*models.Book, error)
+	// FindByISBN looks a book up by its normalized ISBN-13.
+	FindByISBN(ctx context.Context, isbn string) (*models.Book, error)
+	List(ctx context.Context) ([]*models.Book, error)
+	// ListPage returns the page of the books matching query in its order,
+	// with the number of matches and the facet counts of its filter.
+	ListPage(ctx context.Context, query models.BookQuery) (*models.BookPage, error)
+	// Walk calls fn for every book in ID order without building the full
+	// list, stopping at the first error fn returns.
+	Walk(ctx context.Context, fn func(*models.Book) error) error
+	// Create and Update store ISBNs normalized to ISBN-13 and fail with
+	// ErrDuplicateISBN when another book, trashed ones included, has it.
+	Create(ctx context.Context, req models.CreateBookRequest) (*models.Book, error)
+	// Update and Delete only apply when the book is still at version; pass
+	// AnyVersion to skip the check. A stale version yields ErrVersionMismatch.
+	Update(ctx context.Context, id int, req models.UpdateBookRequest, version int) (*models.Book, error)
+	// Delete moves the book to the trash. Trashed books are hidden from
+	// Get, List, Walk, Exists, Search and Update until restored.
+	Delete(ctx context.Context, id int, version int) error
+	Exists(ctx context.Context, id int) (bool, error)
+
+	// Trash lists the trashed books in ID order.
+	Trash(ctx context.Context) ([]*models.Book, error)
+	// Restore takes a book out of the trash. It fails with ErrBookNotInTrash
+	// when the book exists but was not deleted.
+	Restore(ctx context.Context, id int) (*models.Book, error)
+	// Purge permanently removes books trashed before cutoff and reports how
+	// many were removed.
+	Purge(ctx context.Context, cutoff time.Time) (int, error)
+
+	// AuditLog returns the entries recorded for every create, update,
+	// delete, restore and purge that match filter, oldest first. Writes are
+	// attributed to the actor set with WithActor.
+	AuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error)
+
+	// Search runs a full-text query over title, author and description and
+	// returns the matches ordered by relevance.
+	Search(ctx context.Context, query string) ([]*models.ScoredBook, error)
+
+	// ApplyBatch runs ops in order and reports one result per operation. With
+	// atomic set, either every operation is applied or none is.
+	ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]BatchResult, error)
+
+	UserStore
+}
+
+// UserStore keeps the users and their personal shelves. Every shelf
+// operation is scoped to one user so a user can never read or change
+// another user's items. Books in the trash are hidden from shelves and
+// purging a book removes it from every shelf.
+type UserStore interface {
+	// GetUser fails with ErrUserNotFound when no user is called username.
+	GetUser(ctx context.Context, username string) (*models.User, error)
+	// EnsureUser returns the user called username, creating it on first use.
+	EnsureUser(ctx context.Context, username string) (*models.User, error)
+	// UserBooks lists the shelf of userID in book ID order.
+	UserBooks(ctx context.Context, userID int) ([]*models.UserBook, error)
+	// GetUserBook fails with ErrUserBookNotFound when the book is not on the
+	// shelf of userID.
+	GetUserBook(ctx context.Context, userID, bookID int) (*models.UserBook, error)
+	// PutUserBook adds the book to the shelf of userID or updates it, and
+	// reports whether it was added. It fails with ErrBookNotFound when the
+	// book is not in the catalog.
+	PutUserBook(ctx context.Context, userID, bookID int, req models.UserBookRequest) (*models.UserBook, bool, error)
+	// DeleteUserBook takes the book off the shelf of userID; the catalog
+	// entry is left untouched.
+	DeleteUserBook(ctx context.Context, userID, bookID int) error
+}
+
+// catalogStatus returns the deprecated catalog status to store: status when
+// given, fallback otherwise
+func catalogStatus(status, fallback string) string {
+	if status == "" {
+		return fallback
+	}
+	return status
+}
+
+// AnyVersion disables the optimistic concurrency check of Update and Delete
+const AnyVersion = 0
+
+// searchDocument extracts the indexed text of a book
+func searchDocument(book *models.Book) search.Document {
+	return search.Document{
+		Title:       book.Title,
+		Author:      book.Author,
+		Description: book.Description,
+	}
+}
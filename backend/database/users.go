@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"book-library-backend/models"
+)
+
+func (db *InMemoryDB) GetUser(ctx context.Context, username string) (*models.User, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	user, exists := db.users[username]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	c := *user
+	return &c, nil
+}
+
+func (db *InMemoryDB) EnsureUser(ctx context.Context, username string) (*models.User, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	user, exists := db.users[username]
+	if !exists {
+		user = &models.User{ID: db.nextUserID, Username: username, CreatedAt: time.Now().UTC()}
+		db.users[username] = user
+		db.nextUserID++
+	}
+	c := *user
+	return &c, nil
+}
+
+func (db *InMemoryDB) UserBooks(ctx context.Context, userID int) ([]*models.UserBook, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	items := make([]*models.UserBook, 0, len(db.shelves[userID]))
+	for bookID, item := range db.shelves[userID] {
+		if book, exists := db.books[bookID]; exists && book.DeletedAt == nil {
+			items = append(items, copyUserBook(item, book))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].BookID < items[j].BookID })
+	return items, nil
+}
+
+func (db *InMemoryDB) GetUserBook(ctx context.Context, userID, bookID int) (*models.UserBook, error) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	item, onShelf := db.shelves[userID][bookID]
+	book, exists := db.books[bookID]
+	if !onShelf || !exists || book.DeletedAt != nil {
+		return nil, ErrUserBookNotFound
+	}
+	return copyUserBook(item, book), nil
+}
+
+func (db *InMemoryDB) PutUserBook(ctx context.Context, userID, bookID int, req models.UserBookRequest) (*models.UserBook, bool, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	book, exists := db.books[bookID]
+	if !exists || book.DeletedAt != nil {
+		return nil, false, ErrBookNotFound
+	}
+
+	shelf, ok := db.shelves[userID]
+	if !ok {
+		shelf = make(map[int]*models.UserBook)
+		db.shelves[userID] = shelf
+	}
+	current, onShelf := shelf[bookID]
+	item, err := req.Apply(current, userID, bookID, time.Now().UTC())
+	if err != nil {
+		return nil, false, err
+	}
+	shelf[bookID] = item
+
+	return copyUserBook(item, book), !onShelf, nil
+}
+
+func (db *InMemoryDB) DeleteUserBook(ctx context.Context, userID, bookID int) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	book, exists := db.books[bookID]
+	if _, onShelf := db.shelves[userID][bookID]; !onShelf || !exists || book.DeletedAt != nil {
+		return ErrUserBookNotFound
+	}
+	delete(db.shelves[userID], bookID)
+	return nil
+}
+
+// copyUserBook returns a copy of item joined with its catalog book so
+// callers never share the stored pointers
+func copyUserBook(item *models.UserBook, book *models.Book) *models.UserBook {
+	c := *item
+	if item.StartedAt != nil {
+		startedAt := *item.StartedAt
+		c.StartedAt = &startedAt
+	}
+	if item.FinishedAt != nil {
+		finishedAt := *item.FinishedAt
+		c.FinishedAt = &finishedAt
+	}
+	c.Book = copyBook(book)
+	return &c
+}
+
+func (s *SQLStore) GetUser(ctx context.Context, username string) (*models.User, error) {
+	user := &models.User{}
+	err := s.db.QueryRowContext(ctx, "SELECT id, username, created_at FROM users WHERE username = ?", username).
+		Scan(&user.ID, &user.Username, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) EnsureUser(ctx context.Context, username string) (*models.User, error) {
+	var user *models.User
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO users (username, created_at) VALUES (?, ?) ON CONFLICT (username) DO NOTHING",
+			username, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		user = &models.User{}
+		return tx.QueryRowContext(ctx, "SELECT id, username, created_at FROM users WHERE username = ?", username).
+			Scan(&user.ID, &user.Username, &user.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// userBookQuery joins shelf items with their books, leaving out trashed ones
+const userBookQuery = "SELECT ub.user_id, ub.book_id, ub.status, ub.started_at, ub.finished_at, ub.notes, ub.created_at, ub.updated_at, " +
+	"b.id, b.title, b.author, b.isbn, b.year, b.description, b.status, b.version, b.created_at, b.updated_at, b.deleted_at " +
+	"FROM user_books ub JOIN books b ON b.id = ub.book_id WHERE b.deleted_at IS NULL AND ub.user_id = ?"
+
+func scanUserBook(row rowScanner) (*models.UserBook, error) {
+	item := &models.UserBook{Book: &models.Book{}}
+	var startedAt, finishedAt, deletedAt sql.NullTime
+	var isbn sql.NullString
+	err := row.Scan(&item.UserID, &item.BookID, &item.Status, &startedAt, &finishedAt, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+		&item.Book.ID, &item.Book.Title, &item.Book.Author, &isbn, &item.Book.Year, &item.Book.Description,
+		&item.Book.Status, &item.Book.Version, &item.Book.CreatedAt, &item.Book.UpdatedAt, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		item.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		item.FinishedAt = &finishedAt.Time
+	}
+	item.Book.ISBN = isbn.String
+	if deletedAt.Valid {
+		item.Book.DeletedAt = &deletedAt.Time
+	}
+	return item, nil
+}
+
+func (s *SQLStore) UserBooks(ctx context.Context, userID int) ([]*models.UserBook, error) {
+	rows, err := s.db.QueryContext(ctx, userBookQuery+" ORDER BY ub.book_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.UserBook, 0)
+	for rows.Next() {
+		item, err := scanUserBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *SQLStore) GetUserBook(ctx context.Context, userID, bookID int) (*models.UserBook, error) {
+	return getUserBook(ctx, s.db, userID, bookID)
+}
+
+func getUserBook(ctx context.Context, q sqlExecutor, userID, bookID int) (*models.UserBook, error) {
+	item, err := scanUserBook(q.QueryRowContext(ctx, userBookQuery+" AND ub.book_id = ?", userID, bookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserBookNotFound
+	}
+	return item, err
+}
+
+func (s *SQLStore) PutUserBook(ctx context.Context, userID, bookID int, req models.UserBookRequest) (*models.UserBook, bool, error) {
+	var item *models.UserBook
+	created := false
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := getBook(ctx, tx, bookID); err != nil {
+			return err
+		}
+
+		current, err := getUserBook(ctx, tx, userID, bookID)
+		if errors.Is(err, ErrUserBookNotFound) {
+			current, created = nil, true
+		} else if err != nil {
+			return err
+		}
+
+		next, err := req.Apply(current, userID, bookID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO user_books (user_id, book_id, status, started_at, finished_at, notes, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) "+
+				"ON CONFLICT (user_id, book_id) DO UPDATE SET status = excluded.status, started_at = excluded.started_at, "+
+				"finished_at = excluded.finished_at, notes = excluded.notes, updated_at = excluded.updated_at",
+			userID, bookID, next.Status, timeOrNil(next.StartedAt), timeOrNil(next.FinishedAt), next.Notes, next.CreatedAt, next.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		item, err = getUserBook(ctx, tx, userID, bookID)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return item, created, nil
+}
+
+func (s *SQLStore) DeleteUserBook(ctx context.Context, userID, bookID int) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := getUserBook(ctx, tx, userID, bookID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM user_books WHERE user_id = ? AND book_id = ?", userID, bookID)
+		return err
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+var auditActions = []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditRestore, models.AuditPurge}
+
+// auditOrder pages audit entries, which stores return oldest (lowest ID) first
+var auditOrder = idOrder("id", func(entry *models.AuditEntry) int { return entry.ID })
+
+// GetBookHistory handles GET /api/books/{id}/history and returns the audit
+// entries of one book, oldest first. Pagination parameters apply.
+func (h *BookHandler) GetBookHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	logrus.WithField("id", idStr).Info("Fetching book history")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	page, err := parsePageRequest(r.URL.Query())
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	entries, err := h.store.AuditLog(r.Context(), models.AuditFilter{BookID: id})
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingAudit)
+		return
+	}
+
+	// Books that were never changed through the store have no entries yet
+	if len(entries) == 0 {
+		exists, err := h.store.Exists(r.Context(), id)
+		if err == nil && !exists {
+			err = database.ErrBookNotFound
+		}
+		if err != nil {
+			writeStoreError(w, r, err, constants.ErrFetchingAudit)
+			return
+		}
+	}
+
+	pageEntries, pagination, err := paginate(entries, page, auditOrder, r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	utils.WritePaginatedResponse(w, constants.MsgHistoryFetched, pageEntries, pagination, nil)
+}
+
+// GetAuditLog handles GET /api/audit. Entries can be filtered by bookId,
+// actor, action, since (inclusive) and until (exclusive) and are returned
+// oldest first with the usual pagination parameters.
+func (h *BookHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Fetching audit log")
+
+	query := r.URL.Query()
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	page, err := parsePageRequest(query)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	entries, err := h.store.AuditLog(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingAudit)
+		return
+	}
+
+	pageEntries, pagination, err := paginate(entries, page, auditOrder, r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	utils.WritePaginatedResponse(w, constants.MsgAuditFetched, pageEntries, pagination, nil)
+}
+
+func parseAuditFilter(query url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:  strings.TrimSpace(query.Get("actor")),
+		Action: strings.ToLower(strings.TrimSpace(query.Get("action"))),
+	}
+
+	if raw := strings.TrimSpace(query.Get("bookId")); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return filter, errors.New(constants.ErrInvalidID)
+		}
+		filter.BookID = id
+	}
+
+	if filter.Action != "" {
+		valid := false
+		for _, action := range auditActions {
+			valid = valid || action == filter.Action
+		}
+		if !valid {
+			return filter, errors.New(constants.ErrInvalidAuditAction)
+		}
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(query, "since"); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTimeParam(query, "until"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxBatchSize caps the number of operations in a single batch request
+const maxBatchSize = 100
+
+// BatchBooks handles POST /api/books/batch. The body is an array of
+// create/update/delete operations; with ?atomic=true they are applied
+// all-or-nothing, otherwise each succeeds or fails on its own.
+func (h *BookHandler) BatchBooks(w http.ResponseWriter, r *http.Request) {
+	atomic := false
+	if raw := r.URL.Query().Get("atomic"); raw != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, r, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidAtomicFlag))
+			return
+		}
+	}
+
+	// Creates refuse duplicate books the same way POST /api/books does
+	ctx, apiErr := createContext(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	logrus.WithField("atomic", atomic).Info("Processing book batch")
+
+	var ops []models.BatchOperation
+	if apiErr := decodeJSONBody(w, r, &ops); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if len(ops) == 0 {
+		writeError(w, r, badRequest(constants.CodeValidationFailed, constants.ErrEmptyBatch))
+		return
+	}
+	if len(ops) > maxBatchSize {
+		writeError(w, r, models.NewAPIError(http.StatusRequestEntityTooLarge, constants.CodePayloadTooLarge,
+			fmt.Sprintf("%s (%d > %d)", constants.ErrBatchTooLarge, len(ops), maxBatchSize)))
+		return
+	}
+
+	// Validate every operation up front; only valid ones reach the store
+	results := make([]models.BatchItemResult, len(ops))
+	valid := make([]models.BatchOperation, 0, len(ops))
+	validIndexes := make([]int, 0, len(ops))
+	for i, op := range ops {
+		if fields := validateBatchOperation(op); len(fields) > 0 {
+			results[i] = batchItemError(i, op, models.NewValidationError(fields))
+			continue
+		}
+		valid = append(valid, op)
+		validIndexes = append(validIndexes, i)
+	}
+
+	if atomic && len(valid) < len(ops) {
+		for _, i := range validIndexes {
+			results[i] = batchItemError(i, ops[i], apiError(database.ErrBatchRolledBack, ""))
+		}
+		writeBatchResponse(w, r, results, true)
+		return
+	}
+
+	storeResults, err := h.store.ApplyBatch(ctx, valid, atomic)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	failed := false
+	for k, result := range storeResults {
+		i := validIndexes[k]
+		results[i] = batchItemResult(i, ops[i], result)
+		if result.Err != nil {
+			failed = true
+		}
+	}
+
+	writeBatchResponse(w, r, results, atomic && failed)
+}
+
+// validateBatchOperation checks an operation before it reaches the store
+func validateBatchOperation(op models.BatchOperation) []models.FieldError {
+	invalidID := []models.FieldError{{Field: "id", Code: constants.FieldMin, Message: constants.ErrInvalidID}}
+	switch op.Op {
+	case models.BatchCreate:
+		return utils.Validate(op.Book)
+	case models.BatchUpdate:
+		if op.ID <= 0 {
+			return invalidID
+		}
+		return utils.Validate(op.Book)
+	case models.BatchDelete:
+		if op.ID <= 0 {
+			return invalidID
+		}
+		return nil
+	default:
+		return []models.FieldError{{Field: "op", Code: constants.FieldOneOf, Message: constants.ErrInvalidBatchOp}}
+	}
+}
+
+// batchItemResult converts a store result into its HTTP-facing form
+func batchItemResult(index int, op models.BatchOperation, result database.BatchResult) models.BatchItemResult {
+	item := models.BatchItemResult{Index: index, Op: op.Op, ID: op.ID, Book: result.Book}
+	if result.Book != nil {
+		item.ID = result.Book.ID
+	}
+
+	if result.Err == nil {
+		item.Status = http.StatusOK
+		if op.Op == models.BatchCreate {
+			item.Status = http.StatusCreated
+		}
+		return item
+	}
+
+	apiErr := apiError(result.Err, constants.ErrInternalServer)
+	if apiErr.Status >= http.StatusInternalServerError {
+		logrus.WithError(result.Err).WithField("index", index).Error("Batch operation failed")
+	}
+	item.Status, item.Code, item.Error = apiErr.Status, apiErr.Code, apiErr.Message
+	return item
+}
+
+// batchItemError reports an operation rejected with apiErr
+func batchItemError(index int, op models.BatchOperation, apiErr *models.APIError) models.BatchItemResult {
+	return models.BatchItemResult{
+		Index:  index,
+		Op:     op.Op,
+		ID:     op.ID,
+		Status: apiErr.Status,
+		Error:  apiErr.Message,
+		Code:   apiErr.Code,
+		Errors: apiErr.Fields,
+	}
+}
+
+func writeBatchResponse(w http.ResponseWriter, r *http.Request, results []models.BatchItemResult, rolledBack bool) {
+	if rolledBack {
+		apiErr := models.NewAPIError(http.StatusUnprocessableEntity, constants.CodeBatchRolledBack, constants.ErrBatchRolledBack)
+		apiErr.Data = results
+		writeError(w, r, apiErr)
+		return
+	}
+	utils.WriteSuccessResponse(w, constants.MsgBatchProcessed, results)
+}
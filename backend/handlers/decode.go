@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// maxJSONBodyBytes caps the size of JSON request bodies
+const maxJSONBodyBytes = 1 << 20
+
+var errBodyTooLarge = models.NewAPIError(http.StatusRequestEntityTooLarge, constants.CodePayloadTooLarge, constants.ErrBodyTooLarge)
+
+// decodeJSONBody strictly decodes the JSON request body into dst. The body
+// must be application/json (415), at most maxJSONBodyBytes long (413) and a
+// single JSON value whose fields all exist in dst (400). Syntax, type and
+// unknown-field errors report where in the body they occurred.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) *models.APIError {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return models.NewAPIError(http.StatusUnsupportedMediaType, constants.CodeUnsupportedMediaType, constants.ErrUnsupportedBody)
+	}
+
+	body, apiErr := readJSONBody(w, r)
+	if apiErr != nil {
+		return apiErr
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return badRequest(constants.CodeInvalidJSON, constants.ErrEmptyBody)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return jsonDecodeError(body, decoder, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return jsonBodyError(body, decoder.InputOffset()-1, constants.ErrTrailingData, nil)
+	}
+	return nil
+}
+
+// readJSONBody reads the request body, failing with 413 past
+// maxJSONBodyBytes
+func readJSONBody(w http.ResponseWriter, r *http.Request) ([]byte, *models.APIError) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errBodyTooLarge
+		}
+		return nil, errInvalidJSON
+	}
+	return body, nil
+}
+
+// jsonDecodeError describes err, returned by decoder while reading body
+func jsonDecodeError(body []byte, decoder *json.Decoder, err error) *models.APIError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return jsonBodyError(body, syntaxErr.Offset-1, err.Error(), nil)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return jsonBodyError(body, int64(len(body)), err.Error(), nil)
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("%s %s: expected %s, got %s", constants.ErrFieldType, typeErr.Field, typeErr.Type, typeErr.Value)
+		return jsonBodyError(body, typeErr.Offset-1, message,
+			&models.FieldError{Field: typeErr.Field, Code: constants.FieldType, Message: message})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder has no typed error for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		message := fmt.Sprintf("%s %q", constants.ErrUnknownField, field)
+		return jsonBodyError(body, objectKeyOffset(body, field), message,
+			&models.FieldError{Field: field, Code: constants.FieldUnknown, Message: message})
+	default:
+		return jsonBodyError(body, 0, err.Error(), nil)
+	}
+}
+
+// objectKeyOffset returns the offset of the first object key named key in
+// body, which must be valid JSON, or 0 when there is none
+func objectKeyOffset(body []byte, key string) int64 {
+	// scope is an open object or array; objects alternate keys and values
+	type scope struct{ object, expectKey bool }
+	var scopes []scope
+	valueDone := func() {
+		if n := len(scopes); n > 0 && scopes[n-1].object {
+			scopes[n-1].expectKey = true
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return 0
+		}
+
+		if n := len(scopes); n > 0 && scopes[n-1].expectKey && token != json.Delim('}') {
+			if token == key {
+				// start may precede the separator and whitespace
+				return start + int64(bytes.IndexByte(body[start:], '"'))
+			}
+			scopes[n-1].expectKey = false
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'):
+			scopes = append(scopes, scope{object: true, expectKey: true})
+		case json.Delim('['):
+			scopes = append(scopes, scope{})
+		case json.Delim('}'), json.Delim(']'):
+			scopes = scopes[:len(scopes)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
+
+// jsonBodyError reports a 400 located at the byte at offset in body
+func jsonBodyError(body []byte, offset int64, message string, field *models.FieldError) *models.APIError {
+	position := bodyPosition(body, offset)
+	apiErr := badRequest(constants.CodeInvalidJSON,
+		fmt.Sprintf("%s at line %d, column %d: %s", constants.ErrInvalidJSON, position.Line, position.Column, message))
+	apiErr.Data = position
+	if field != nil {
+		apiErr.Fields = []models.FieldError{*field}
+	}
+	return apiErr
+}
+
+// bodyPosition converts a byte offset into a line and column
+func bodyPosition(body []byte, offset int64) models.BodyPosition {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(body)) {
+		offset = int64(len(body))
+	}
+	before := body[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return models.BodyPosition{Offset: offset, Line: line, Column: column}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/search"
+	"book-library-backend/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDuplicateThreshold is the similarity from which two books are
+// reported by GET /api/books/duplicates
+const defaultDuplicateThreshold = 0.85
+
+// duplicateOrder pages duplicate groups, ordered by their lowest book ID
+var duplicateOrder = idOrder("book_id", func(group models.DuplicateGroup) int { return group.Books[0].ID })
+
+// Title similarity weighs more than author similarity since authors are
+// often written with or without initials.
+const (
+	titleSimilarityWeight  = 0.7
+	authorSimilarityWeight = 0.3
+)
+
+// isbnKey identifies a book by its normalized ISBN in the same key space as
+// database.DuplicateKey
+func isbnKey(isbn string) string {
+	return "isbn\x00" + isbn
+}
+
+// findISBNOwner returns the book outside the trash with the same ISBN as
+// req, or nil when there is none. The store still rejects a conflicting ISBN
+// on create; looking it up first lets the 409 point at the existing book.
+func (h *BookHandler) findISBNOwner(ctx context.Context, req models.CreateBookRequest) (*models.Book, error) {
+	isbn, _ := utils.NormalizeISBN(req.ISBN)
+	if isbn == "" {
+		return nil, nil
+	}
+	book, err := h.store.FindByISBN(ctx, isbn)
+	if errors.Is(err, database.ErrBookNotFound) {
+		return nil, nil
+	}
+	return book, err
+}
+
+// writeDuplicateResponse reports a 409 pointing at the existing book
+func writeDuplicateResponse(w http.ResponseWriter, r *http.Request, existingID int) {
+	apiErr := models.NewAPIError(http.StatusConflict, constants.CodeBookAlreadyExists, constants.ErrBookAlreadyExists)
+	apiErr.Data = map[string]int{"existing_id": existingID}
+	writeError(w, r, apiErr)
+}
+
+// createContext returns the context books of r are created with, which
+// rejects a matching title and author unless r asks for ?force=true
+func createContext(r *http.Request) (context.Context, *models.APIError) {
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		var err error
+		if force, err = strconv.ParseBool(raw); err != nil {
+			return nil, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidForceFlag)
+		}
+	}
+	if force {
+		return r.Context(), nil
+	}
+	return database.RejectDuplicates(r.Context()), nil
+}
+
+// maxDuplicateBlock caps how many books sharing a blocking key are compared
+// with each other. Keys shared by more books, such as a common title word,
+// say too little about duplicates to be worth the quadratic cost.
+const maxDuplicateBlock = 500
+
+// duplicateCandidate is a book with its title and author normalized once
+type duplicateCandidate struct {
+	title  []rune
+	author []rune
+	keys   []string
+}
+
+func newDuplicateCandidate(book *models.Book) duplicateCandidate {
+	c := duplicateCandidate{
+		title:  []rune(search.Normalize(book.Title)),
+		author: []rune(search.Normalize(book.Author)),
+	}
+	c.keys = c.blockingKeys()
+	return c
+}
+
+// blockingKeys lists the keys under which a book is compared with others:
+// its title terms, the start of its title and its author. Books similar
+// enough to be reported share at least one of them in practice.
+func (c duplicateCandidate) blockingKeys() []string {
+	keys := []string{"author:" + string(c.author)}
+	prefix := c.title
+	if len(prefix) > 4 {
+		prefix = prefix[:4]
+	}
+	keys = append(keys, "prefix:"+string(prefix))
+	for _, term := range search.Tokenize(string(c.title)) {
+		keys = append(keys, "term:"+term)
+	}
+	return keys
+}
+
+// similarityBound is the highest similarity a and b can reach: the edit
+// distance is at least the difference between the lengths
+func similarityBound(a, b duplicateCandidate) float64 {
+	return titleSimilarityWeight*lengthRatio(a.title, b.title) + authorSimilarityWeight*lengthRatio(a.author, b.author)
+}
+
+func lengthRatio(a, b []rune) float64 {
+	shortest, longest := len(a), len(b)
+	if shortest > longest {
+		shortest, longest = longest, shortest
+	}
+	if longest == 0 {
+		return 1
+	}
+	return float64(shortest) / float64(longest)
+}
+
+// bookSimilarity scores how likely a and b describe the same book
+func bookSimilarity(a, b duplicateCandidate) float64 {
+	score := titleSimilarityWeight*search.RuneSimilarity(a.title, b.title) +
+		authorSimilarityWeight*search.RuneSimilarity(a.author, b.author)
+	return math.Round(score*1000) / 1000
+}
+
+// FindDuplicates handles GET /api/books/duplicates. Books whose title and
+// author are at least ?threshold= similar (0-1, default 0.85) are grouped
+// together; books without a likely duplicate are left out. Groups are
+// paginated like book listings, 100 per page by default.
+func (h *BookHandler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := defaultDuplicateThreshold
+	if raw := r.URL.Query().Get("threshold"); raw != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			writeError(w, r, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidThreshold))
+			return
+		}
+	}
+	page, err := parsePageRequest(r.URL.Query())
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	if page.limit == 0 {
+		page.limit = maxPageLimit
+	}
+
+	logrus.WithField("threshold", threshold).Info("Finding duplicate books")
+
+	books, err := h.store.List(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	groups, pagination, err := paginate(groupDuplicates(books, threshold), page, duplicateOrder, r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	utils.WritePaginatedResponse(w, constants.MsgDuplicatesFetched, groups, pagination, nil)
+}
+
+// groupDuplicates links the pairs of books at least threshold similar and
+// returns the connected groups ordered by their lowest book ID. Only books
+// sharing a blocking key are compared, so the cost grows with the size of
+// the blocks rather than with the square of the catalog.
+func groupDuplicates(books []*models.Book, threshold float64) []models.DuplicateGroup {
+	candidates := make([]duplicateCandidate, len(books))
+	blocks := make(map[string][]int)
+	parent := make([]int, len(books))
+	score := make([]float64, len(books))
+	for i, book := range books {
+		candidates[i] = newDuplicateCandidate(book)
+		for _, key := range candidates[i].keys {
+			blocks[key] = append(blocks[key], i)
+		}
+		parent[i] = i
+		score[i] = 1
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i, candidate := range candidates {
+		compared := make(map[int]bool)
+		for _, key := range candidate.keys {
+			block := blocks[key]
+			if len(block) > maxDuplicateBlock {
+				continue
+			}
+			for _, j := range block {
+				if j <= i || compared[j] {
+					continue
+				}
+				compared[j] = true
+				if similarityBound(candidate, candidates[j]) < threshold {
+					continue
+				}
+				similarity := bookSimilarity(candidate, candidates[j])
+				if similarity < threshold {
+					continue
+				}
+				ri, rj := find(i), find(j)
+				if ri != rj {
+					parent[rj] = ri
+					score[ri] = math.Min(score[ri], score[rj])
+				}
+				score[ri] = math.Min(score[ri], similarity)
+			}
+		}
+	}
+
+	members := make(map[int][]*models.Book)
+	for i, book := range books {
+		root := find(i)
+		members[root] = append(members[root], book)
+	}
+
+	groups := make([]models.DuplicateGroup, 0)
+	for root, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].ID < group[j].ID })
+		groups = append(groups, models.DuplicateGroup{Score: score[root], Books: group})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Books[0].ID < groups[j].Books[0].ID })
+	return groups
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// storeErrors maps the errors a BookStore can return to the status and code
+// reported to clients
+var storeErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{database.ErrBookNotFound, http.StatusNotFound, constants.CodeBookNotFound},
+	{database.ErrVersionMismatch, http.StatusPreconditionFailed, constants.CodeVersionMismatch},
+	{database.ErrBookNotInTrash, http.StatusConflict, constants.CodeBookNotInTrash},
+	{database.ErrDuplicateISBN, http.StatusConflict, constants.CodeDuplicateISBN},
+	{database.ErrDuplicateBook, http.StatusConflict, constants.CodeBookAlreadyExists},
+	{database.ErrUserBookNotFound, http.StatusNotFound, constants.CodeUserBookNotFound},
+	{database.ErrBatchRolledBack, http.StatusFailedDependency, constants.CodeBatchRolledBack},
+	{utils.ErrInvalidISBN, http.StatusBadRequest, constants.CodeValidationFailed},
+}
+
+// apiError converts err into an APIError. Known store errors keep their own
+// message; anything else is an internal error reported as fallback.
+func apiError(err error, fallback string) *models.APIError {
+	var apiErr *models.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	for _, known := range storeErrors {
+		if errors.Is(err, known.err) {
+			return &models.APIError{Status: known.status, Code: known.code, Message: known.err.Error(), Err: err}
+		}
+	}
+	return &models.APIError{
+		Status:  http.StatusInternalServerError,
+		Code:    constants.CodeInternal,
+		Message: fallback,
+		Err:     err,
+	}
+}
+
+// writeError writes apiErr to the client, logging internal errors first
+func writeError(w http.ResponseWriter, r *http.Request, apiErr *models.APIError) {
+	if apiErr.Status >= http.StatusInternalServerError {
+		logrus.WithError(apiErr.Err).WithField("path", r.URL.Path).Error(apiErr.Message)
+	}
+	utils.WriteError(w, r, apiErr)
+}
+
+// writeStoreError reports an error returned by the store, see apiError
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	writeError(w, r, apiError(err, fallback))
+}
+
+// badRequest reports an invalid query parameter or path segment
+func badRequest(code, message string) *models.APIError {
+	return models.NewAPIError(http.StatusBadRequest, code, message)
+}
+
+var (
+	errInvalidID        = badRequest(constants.CodeInvalidID, constants.ErrInvalidID)
+	errInvalidJSON      = badRequest(constants.CodeInvalidJSON, constants.ErrInvalidJSON)
+	errUnsupportedPatch = models.NewAPIError(http.StatusUnsupportedMediaType, constants.CodeUnsupportedMediaType, constants.ErrUnsupportedPatch)
+)
+
+// invalidParameter reports a query parameter that failed to parse
+func invalidParameter(err error) *models.APIError {
+	return badRequest(constants.CodeInvalidParameter, err.Error())
+}
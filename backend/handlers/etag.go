@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"book-library-backend/models"
+)
+
+// bookETag derives the strong entity tag of a book from its ID and version
+func bookETag(book *models.Book) string {
+	return fmt.Sprintf(`"%d.%d"`, book.ID, book.Version)
+}
+
+// setBookETag advertises the current version of book on the response
+func setBookETag(w http.ResponseWriter, book *models.Book) {
+	w.Header().Set("ETag", bookETag(book))
+}
+
+// etagListMatches reports whether etag appears in an If-Match or
+// If-None-Match header value. With weak set, W/ prefixes are ignored as
+// required for If-None-Match; If-Match uses strong comparison.
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch validates the If-Match precondition against the current book.
+// It returns the version writes must be conditioned on, or false when the
+// precondition failed.
+func checkIfMatch(r *http.Request, current *models.Book) (int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return 0, true
+	}
+	if !etagListMatches(header, bookETag(current), false) {
+		return 0, false
+	}
+	return current.Version, true
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// exportFlushEvery controls how often streamed exports are flushed
+const exportFlushEvery = 100
+
+// exportWriteWindow is how long the client gets to take each flushed batch.
+// Every flush pushes the write deadline back by this much, so the server's
+// WriteTimeout cuts off stalled clients rather than large exports.
+const exportWriteWindow = 30 * time.Second
+
+// bookExporter writes books one at a time in a specific format
+type bookExporter interface {
+	begin() error
+	write(book *models.Book) error
+	end() error
+}
+
+// exportFormat describes one format offered by GET /api/books/export
+type exportFormat struct {
+	name        string
+	contentType string
+	extension   string
+	accepts     []string
+	newExporter func(w io.Writer) bookExporter
+}
+
+var exportFormats = []exportFormat{
+	{
+		name: "ndjson", contentType: "application/x-ndjson", extension: "ndjson",
+		accepts:     []string{"application/x-ndjson", "application/jsonl", "application/json"},
+		newExporter: func(w io.Writer) bookExporter { return &ndjsonExporter{encoder: json.NewEncoder(w)} },
+	},
+	{
+		name: "csv", contentType: "text/csv", extension: "csv",
+		accepts:     []string{"text/csv"},
+		newExporter: func(w io.Writer) bookExporter { return &csvExporter{writer: csv.NewWriter(w)} },
+	},
+	{
+		name: "dc", contentType: "application/xml", extension: "xml",
+		accepts:     []string{"application/xml", "text/xml"},
+		newExporter: func(w io.Writer) bookExporter { return &dublinCoreExporter{w: w, encoder: xml.NewEncoder(w)} },
+	},
+	{
+		name: "marcxml", contentType: "application/marcxml+xml", extension: "xml",
+		accepts:     []string{"application/marcxml+xml"},
+		newExporter: func(w io.Writer) bookExporter { return &marcExporter{w: w, encoder: xml.NewEncoder(w)} },
+	},
+}
+
+// ExportBooks handles GET /api/books/export. The format comes from ?format=
+// (ndjson, csv, dc, marcxml) or the Accept header and defaults to NDJSON.
+// The listing filters, search and sort parameters apply; pagination does not.
+// Unsorted exports stream straight from the store.
+func (h *BookHandler) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	format, apiErr := negotiateExportFormat(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	lq, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	logrus.WithField("format", format.name).Info("Exporting books")
+
+	// Sorting and search need the whole result set; otherwise stream
+	var books []*models.Book
+	if !lq.inStoreOrder() {
+		candidates, _, err := h.candidateBooks(r.Context(), lq)
+		if err != nil {
+			writeStoreError(w, r, err, constants.ErrFetchingBooks)
+			return
+		}
+		books = lq.apply(candidates)
+	}
+
+	w.Header().Set("Content-Type", format.contentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="books-%s.%s"`,
+		time.Now().UTC().Format("20060102"), format.extension))
+	w.WriteHeader(http.StatusOK)
+
+	// Not every writer supports deadlines or flushing; those just buffer
+	controller := http.NewResponseController(w)
+	_ = controller.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+
+	exporter := format.newExporter(w)
+	written := 0
+	emit := func(book *models.Book) error {
+		if err := exporter.write(book); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			_ = controller.Flush()
+			_ = controller.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+		}
+		return nil
+	}
+
+	err = exporter.begin()
+	if err == nil {
+		if books != nil {
+			for _, book := range books {
+				if err = emit(book); err != nil {
+					break
+				}
+			}
+		} else {
+			err = h.store.Walk(r.Context(), func(book *models.Book) error {
+				if !lq.filter.Matches(book) {
+					return nil
+				}
+				return emit(book)
+			})
+		}
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err != nil {
+		// Headers are already sent; all we can do is stop and log
+		logrus.WithError(err).Error("Export aborted")
+	}
+}
+
+// negotiateExportFormat picks the export format from ?format= or Accept
+func negotiateExportFormat(r *http.Request) (exportFormat, *models.APIError) {
+	if name := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); name != "" {
+		for _, format := range exportFormats {
+			if format.name == name {
+				return format, nil
+			}
+		}
+		return exportFormat{}, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidExportFormat)
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return exportFormats[0], nil
+	}
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		if mediaType == "*/*" {
+			return exportFormats[0], nil
+		}
+		for _, format := range exportFormats {
+			for _, accepted := range format.accepts {
+				if accepted == mediaType {
+					return format, nil
+				}
+			}
+		}
+	}
+	return exportFormat{}, models.NewAPIError(http.StatusNotAcceptable, constants.CodeNotAcceptable, constants.ErrInvalidExportFormat)
+}
+
+// ndjsonExporter writes one JSON object per line
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) begin() error                  { return nil }
+func (e *ndjsonExporter) write(book *models.Book) error { return e.encoder.Encode(book) }
+func (e *ndjsonExporter) end() error                    { return nil }
+
+// csvExporter writes a header line followed by one row per book
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+var csvExportHeader = []string{"id", "title", "author", "isbn", "year", "description", "status", "version", "created_at", "updated_at"}
+
+func (e *csvExporter) begin() error {
+	return e.writer.Write(csvExportHeader)
+}
+
+func (e *csvExporter) write(book *models.Book) error {
+	return e.writer.Write([]string{
+		strconv.Itoa(book.ID),
+		csvText(book.Title),
+		csvText(book.Author),
+		csvText(book.ISBN),
+		strconv.Itoa(book.Year),
+		csvText(book.Description),
+		csvText(book.Status),
+		strconv.Itoa(book.Version),
+		book.CreatedAt.UTC().Format(time.RFC3339),
+		book.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvExporter) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// csvText escapes text cells that spreadsheets would run as a formula by
+// prefixing them with a quote
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// dublinCoreRecord is a simple Dublin Core description of a book
+type dublinCoreRecord struct {
+	XMLName     xml.Name `xml:"record"`
+	Identifiers []string `xml:"dc:identifier"`
+	Title       string   `xml:"dc:title"`
+	Creator     string   `xml:"dc:creator"`
+	Date        int      `xml:"dc:date"`
+	Description string   `xml:"dc:description,omitempty"`
+	Type        string   `xml:"dc:type"`
+}
+
+// dublinCoreExporter wraps Dublin Core records in a <records> element
+type dublinCoreExporter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+}
+
+func (e *dublinCoreExporter) begin() error {
+	_, err := io.WriteString(e.w, xml.Header+`<records xmlns:dc="http://purl.org/dc/elements/1.1/">`+"\n")
+	return err
+}
+
+func (e *dublinCoreExporter) write(book *models.Book) error {
+	identifiers := []string{strconv.Itoa(book.ID)}
+	if book.ISBN != "" {
+		identifiers = append(identifiers, "urn:isbn:"+book.ISBN)
+	}
+
+	err := e.encoder.Encode(dublinCoreRecord{
+		Identifiers: identifiers,
+		Title:       book.Title,
+		Creator:     book.Author,
+		Date:        book.Year,
+		Description: book.Description,
+		Type:        "Text",
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *dublinCoreExporter) end() error {
+	_, err := io.WriteString(e.w, "</records>\n")
+	return err
+}
+
+// marcRecord is a minimal MARC 21 bibliographic record in MARCXML
+type marcRecord struct {
+	XMLName       xml.Name           `xml:"record"`
+	Leader        string             `xml:"leader"`
+	ControlFields []marcControlField `xml:"controlfield"`
+	DataFields    []marcDataField    `xml:"datafield"`
+}
+
+type marcControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcDataField struct {
+	Tag       string         `xml:"tag,attr"`
+	Ind1      string         `xml:"ind1,attr"`
+	Ind2      string         `xml:"ind2,attr"`
+	Subfields []marcSubfield `xml:"subfield"`
+}
+
+type marcSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// marcExporter writes a MARCXML collection with ISBN (020), author (100),
+// title (245), publication year (264) and summary (520) fields
+type marcExporter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+}
+
+func (e *marcExporter) begin() error {
+	_, err := io.WriteString(e.w, xml.Header+`<collection xmlns="http://www.loc.gov/MARC21/slim">`+"\n")
+	return err
+}
+
+func (e *marcExporter) write(book *models.Book) error {
+	field := func(tag, ind1, ind2, code, value string) marcDataField {
+		return marcDataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: []marcSubfield{{Code: code, Value: value}}}
+	}
+
+	record := marcRecord{
+		Leader:        "00000nam a2200000 i 4500",
+		ControlFields: []marcControlField{{Tag: "001", Value: strconv.Itoa(book.ID)}},
+	}
+	if book.ISBN != "" {
+		record.DataFields = append(record.DataFields, field("020", " ", " ", "a", book.ISBN))
+	}
+	record.DataFields = append(record.DataFields,
+		field("100", "1", " ", "a", book.Author),
+		field("245", "1", "0", "a", book.Title),
+		field("264", " ", "1", "c", strconv.Itoa(book.Year)),
+	)
+	if book.Description != "" {
+		record.DataFields = append(record.DataFields, field("520", " ", " ", "a", book.Description))
+	}
+
+	if err := e.encoder.Encode(record); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *marcExporter) end() error {
+	_, err := io.WriteString(e.w, "</collection>\n")
+	return err
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// parseBookFilter reads title, author, status (repeatable or comma
+// separated), yearFrom/yearTo and createdAfter/updatedSince from the query.
+func parseBookFilter(query url.Values) (models.BookFilter, error) {
+	f := models.BookFilter{
+		Title:  strings.ToLower(strings.TrimSpace(query.Get("title"))),
+		Author: strings.ToLower(strings.TrimSpace(query.Get("author"))),
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if status == "" {
+				continue
+			}
+			if !models.IsValidStatus(status) {
+				return f, fmt.Errorf("%s: %q", constants.ErrInvalidStatusFilter, status)
+			}
+			if f.Statuses == nil {
+				f.Statuses = make(map[string]bool)
+			}
+			f.Statuses[status] = true
+		}
+	}
+
+	var err error
+	if f.YearFrom, err = parseYearParam(query, "yearFrom"); err != nil {
+		return f, err
+	}
+	if f.YearTo, err = parseYearParam(query, "yearTo"); err != nil {
+		return f, err
+	}
+	if f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo {
+		return f, errors.New(constants.ErrInvalidYearRange)
+	}
+
+	if f.CreatedAfter, err = parseTimeParam(query, "createdAfter"); err != nil {
+		return f, err
+	}
+	if f.UpdatedSince, err = parseTimeParam(query, "updatedSince"); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+func parseYearParam(query url.Values, name string) (int, error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return 0, nil
+	}
+	year, err := strconv.Atoi(raw)
+	if err != nil || year < 0 {
+		return 0, fmt.Errorf("%s must be a valid year", name)
+	}
+	return year, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain YYYY-MM-DD dates (UTC)
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+}
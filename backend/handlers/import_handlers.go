@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/search"
+	"book-library-backend/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Import limits
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 5000
+)
+
+var errImportTooLarge = models.NewAPIError(http.StatusRequestEntityTooLarge, constants.CodePayloadTooLarge, constants.ErrImportTooLarge)
+
+// defaultImportStatus is used for rows that do not specify a status
+const defaultImportStatus = models.DefaultCatalogStatus
+
+// csvHeaderAliases maps normalized CSV header names to book fields
+var csvHeaderAliases = map[string]string{
+	"title":            "title",
+	"name":             "title",
+	"book":             "title",
+	"book title":       "title",
+	"author":           "author",
+	"authors":          "author",
+	"writer":           "author",
+	"by":               "author",
+	"year":             "year",
+	"published":        "year",
+	"publication year": "year",
+	"year published":   "year",
+	"description":      "description",
+	"summary":          "description",
+	"notes":            "description",
+	"status":           "status",
+	"isbn":             "isbn",
+	"isbn 13":          "isbn",
+	"isbn13":           "isbn",
+	"isbn 10":          "isbn",
+	"isbn10":           "isbn",
+	"reading status":   "status",
+	"shelf":            "status",
+}
+
+// importRow is a parsed row waiting to be validated and stored
+type importRow struct {
+	row    int
+	book   models.CreateBookRequest
+	errors []string
+}
+
+// ImportBooks handles POST /api/books/import. It accepts text/csv (first line
+// is the header, columns are matched by name or through map=Column:field
+// parameters) or a JSON array of books. Every row is validated like POST
+// /api/books, duplicates of existing books or earlier rows are skipped, and
+// ?dryRun=true reports the outcome without creating anything.
+func (h *BookHandler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	dryRun := false
+	if raw := query.Get("dryRun"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, r, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidDryRunFlag))
+			return
+		}
+	}
+
+	logrus.WithField("dry_run", dryRun).Info("Importing books")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var rows []importRow
+	var err error
+	switch mediaType {
+	case "text/csv":
+		rows, err = parseCSVImport(body, query["map"])
+	case "application/json":
+		rows, err = parseJSONImport(body)
+	default:
+		writeError(w, r, models.NewAPIError(http.StatusUnsupportedMediaType, constants.CodeUnsupportedMediaType, constants.ErrUnsupportedImport))
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, errImportTooLarge)
+			return
+		}
+		writeError(w, r, badRequest(constants.CodeInvalidImport, err.Error()))
+		return
+	}
+	if len(rows) > maxImportRows {
+		writeError(w, r, errImportTooLarge)
+		return
+	}
+
+	existing, err := h.store.List(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+	knownBooks := make(map[string]string, len(existing))
+	for _, book := range existing {
+		duplicate := fmt.Sprintf("%s (id %d)", constants.ErrDuplicateInLibrary, book.ID)
+		knownBooks[database.DuplicateKey(book.Title, book.Author)] = duplicate
+		if book.ISBN != "" {
+			knownBooks[isbnKey(book.ISBN)] = duplicate
+		}
+	}
+
+	report := models.ImportReport{DryRun: dryRun, Rows: make([]models.ImportRowResult, 0, len(rows))}
+	for _, row := range rows {
+		result := models.ImportRowResult{Row: row.row, Title: row.book.Title}
+
+		if row.book.Status == "" {
+			row.book.Status = defaultImportStatus
+		}
+		row.errors = append(row.errors, models.FieldMessages(utils.Validate(row.book))...)
+		key := database.DuplicateKey(row.book.Title, row.book.Author)
+		isbn, _ := utils.NormalizeISBN(row.book.ISBN)
+		if isbn != "" && knownBooks[isbnKey(isbn)] != "" {
+			key = isbnKey(isbn)
+		}
+
+		switch {
+		case len(row.errors) > 0:
+			result.Status = models.ImportRejected
+			result.Errors = row.errors
+			report.Rejected++
+		case knownBooks[key] != "":
+			result.Status = models.ImportSkipped
+			result.Errors = []string{knownBooks[key]}
+			report.Skipped++
+		default:
+			if !dryRun {
+				book, err := h.store.Create(database.RejectDuplicates(r.Context()), row.book)
+				var duplicate *database.DuplicateBookError
+				if errors.As(err, &duplicate) {
+					result.Status = models.ImportSkipped
+					result.Errors = []string{fmt.Sprintf("%s (id %d)", constants.ErrDuplicateInLibrary, duplicate.ExistingID)}
+					report.Skipped++
+					break
+				}
+				if err != nil {
+					result.Status = models.ImportRejected
+					result.Errors = []string{constants.ErrCreatingBook}
+					if errors.Is(err, database.ErrDuplicateISBN) {
+						result.Errors = []string{constants.ErrDuplicateISBN}
+					} else {
+						logrus.WithError(err).WithField("row", row.row).Error("Failed to import book")
+					}
+					report.Rejected++
+					break
+				}
+				result.ID = book.ID
+			}
+			result.Status = models.ImportCreated
+			knownBooks[key] = fmt.Sprintf("%s %d", constants.ErrDuplicateInImport, row.row)
+			if isbn != "" {
+				knownBooks[isbnKey(isbn)] = knownBooks[key]
+			}
+			report.Created++
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+
+	utils.WriteSuccessResponse(w, constants.MsgBooksImported, report)
+}
+
+// parseCSVImport reads a CSV document whose first line names the columns.
+// mappings are "Column:field" overrides for headers the aliases don't cover.
+func parseCSVImport(body io.Reader, mappings []string) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New(constants.ErrEmptyImport)
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	aliases := make(map[string]string, len(csvHeaderAliases)+len(mappings))
+	for name, field := range csvHeaderAliases {
+		aliases[name] = field
+	}
+	for _, mapping := range mappings {
+		column, field, ok := strings.Cut(mapping, ":")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || !isBookField(field) {
+			return nil, fmt.Errorf("%s: %q", constants.ErrInvalidColumnMapping, mapping)
+		}
+		aliases[search.Normalize(column)] = field
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		field, ok := aliases[search.Normalize(name)]
+		if !ok {
+			continue
+		}
+		if _, dup := columns[field]; dup {
+			return nil, fmt.Errorf("%s: %s", constants.ErrDuplicateColumn, field)
+		}
+		columns[field] = i
+	}
+	for _, required := range []string{"title", "author", "year"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%s: %s", constants.ErrMissingColumn, required)
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := importRow{
+			row: line,
+			book: models.CreateBookRequest{
+				Title:       cell(record, "title"),
+				Author:      cell(record, "author"),
+				ISBN:        cell(record, "isbn"),
+				Description: cell(record, "description"),
+				Status:      cell(record, "status"),
+			},
+		}
+		if rawYear := cell(record, "year"); rawYear != "" {
+			year, err := strconv.Atoi(rawYear)
+			if err != nil {
+				row.errors = append(row.errors, constants.ErrInvalidYear)
+			}
+			row.book.Year = year
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New(constants.ErrEmptyImport)
+	}
+	return rows, nil
+}
+
+// parseJSONImport reads a JSON array of books. The array must be the whole
+// body; each record is decoded on its own, rejecting unknown fields and
+// wrong types, so that one bad record only rejects its own row.
+func parseJSONImport(body io.Reader) ([]importRow, error) {
+	decoder := json.NewDecoder(body)
+	var records []json.RawMessage
+	if err := decoder.Decode(&records); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, errors.New(constants.ErrInvalidJSON)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, errors.New(constants.ErrTrailingData)
+	}
+	if len(records) == 0 {
+		return nil, errors.New(constants.ErrEmptyImport)
+	}
+
+	rows := make([]importRow, len(records))
+	for i, record := range records {
+		rows[i] = importRow{row: i + 1}
+		if err := decodeImportRecord(record, &rows[i].book); err != nil {
+			rows[i].errors = append(rows[i].errors, err.Error())
+		}
+	}
+	return rows, nil
+}
+
+// decodeImportRecord decodes one record of a JSON import as strictly as
+// decodeJSONBody decodes a request body
+func decodeImportRecord(record json.RawMessage, book *models.CreateBookRequest) error {
+	decoder := json.NewDecoder(bytes.NewReader(record))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(book)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return fmt.Errorf("%s: expected a book object, got %s", constants.ErrInvalidJSON, typeErr.Value)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%s %s: expected %s, got %s", constants.ErrFieldType, typeErr.Field, typeErr.Type, typeErr.Value)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return fmt.Errorf("%s %q", constants.ErrUnknownField, field)
+	}
+	return fmt.Errorf("%s: %v", constants.ErrInvalidJSON, err)
+}
+
+func csvError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return err
+	}
+	return fmt.Errorf("%s: %v", constants.ErrInvalidCSV, err)
+}
+
+func isBookField(field string) bool {
+	switch field {
+	case "title", "author", "isbn", "year", "description", "status":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// listQuery holds the search, filter and ordering parameters shared by
+// GET /api/books and GET /api/books/export
+type listQuery struct {
+	search       string
+	filter       models.BookFilter
+	sortKeys     []models.SortKey
+	explicitSort bool
+}
+
+func parseListQuery(query url.Values) (listQuery, error) {
+	lq := listQuery{
+		search:       strings.TrimSpace(query.Get("q")),
+		explicitSort: hasExplicitSort(query),
+	}
+
+	var err error
+	if lq.filter, err = parseBookFilter(query); err != nil {
+		return lq, err
+	}
+	if lq.sortKeys, err = parseSort(query); err != nil {
+		return lq, err
+	}
+	return lq, nil
+}
+
+// candidateBooks returns the books filters apply to: the search hits (in
+// relevance order, with their scores) when q is set, otherwise the library.
+func (h *BookHandler) candidateBooks(ctx context.Context, lq listQuery) ([]*models.Book, map[int]float64, error) {
+	scores := make(map[int]float64)
+	if lq.search == "" {
+		books, err := h.store.List(ctx)
+		return books, scores, err
+	}
+
+	results, err := h.store.Search(ctx, lq.search)
+	if err != nil {
+		return nil, nil, err
+	}
+	books := make([]*models.Book, 0, len(results))
+	for _, result := range results {
+		books = append(books, result.Book)
+		scores[result.ID] = result.Score
+	}
+	return books, scores, nil
+}
+
+// apply filters candidates and orders them; search results keep their
+// relevance order unless a sort is requested
+func (lq listQuery) apply(candidates []*models.Book) []*models.Book {
+	books := make([]*models.Book, 0)
+	for _, book := range candidates {
+		if lq.filter.Matches(book) {
+			books = append(books, book)
+		}
+	}
+
+	if lq.search == "" || lq.explicitSort {
+		sortBooks(books, lq.sortKeys)
+	}
+	return books
+}
+
+// pageOrder returns the order apply leaves books in, given the relevance
+// scores of search hits
+func (lq listQuery) pageOrder(scores map[int]float64) pageOrder[*models.Book] {
+	if lq.search == "" || lq.explicitSort {
+		return bookOrder(lq.sortKeys)
+	}
+	return relevanceOrder(scores)
+}
+
+// bookQuery is the store query for the page of a listing without search.
+// A cursor is turned into the book its key was taken from.
+func (lq listQuery) bookQuery(page pageRequest) (models.BookQuery, error) {
+	query := models.BookQuery{Filter: lq.filter, Sort: lq.sortKeys, Limit: page.limit, Offset: page.offset}
+	if page.cursor == nil {
+		return query, nil
+	}
+	if page.cursor.Order != bookOrder(lq.sortKeys).spec {
+		return query, errors.New(constants.ErrInvalidCursor)
+	}
+	seek, err := decodeBookCursorKey(page.cursor.Key)
+	if err != nil {
+		return query, errors.New(constants.ErrInvalidCursor)
+	}
+	query.Seek, query.Before = seek, page.cursor.Before
+	return query, nil
+}
+
+// inStoreOrder reports whether results come out in the store's natural ID
+// order, which lets exports stream straight from the store.
+func (lq listQuery) inStoreOrder() bool {
+	return lq.search == "" && len(lq.sortKeys) == 1 && lq.sortKeys[0].Field == "id" && !lq.sortKeys[0].Desc
+}
@@ -1,84 +1,92 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"book-library-backend/constants"
 	"book-library-backend/database"
 	"book-library-backend/models"
 	"book-library-backend/utils"
-	"sort"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// BookHandler serves the /api/books routes on top of an injected BookStore.
+type BookHandler struct {
+	store database.BookStore
+}
+
+// NewBookHandler creates a BookHandler backed by the given store.
+func NewBookHandler(store database.BookStore) *BookHandler {
+	return &BookHandler{store: store}
+}
+
 // GetAllBooks handles GET /api/books
-func GetAllBooks(w http.ResponseWriter, r *http.Request) {
+func (h *BookHandler) GetAllBooks(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Fetching all books")
 
-       // Parse query params for filtering and ordering
-       query := r.URL.Query()
-       filterTitle := strings.TrimSpace(query.Get("title"))
-       filterAuthor := strings.TrimSpace(query.Get("author"))
-       orderBy := strings.TrimSpace(query.Get("orderBy")) // e.g., "title", "author", "year"
-       orderDir := strings.ToLower(strings.TrimSpace(query.Get("orderDir"))) // "asc" or "desc"
-
-       books, err := database.GetAllBooks()
-       if err != nil {
-	       logrus.WithError(err).Error("Failed to fetch books")
-	       utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to fetch books")
-	       return
-       }
-
-       // Filtering
-       filteredBooks := make([]*models.Book, 0)
-       for _, book := range books {
-	       if filterTitle != "" && !strings.Contains(strings.ToLower(book.Title), strings.ToLower(filterTitle)) {
-		       continue
-	       }
-	       if filterAuthor != "" && !strings.Contains(strings.ToLower(book.Author), strings.ToLower(filterAuthor)) {
-		       continue
-	       }
-	       filteredBooks = append(filteredBooks, book)
-       }
-
-       // Ordering
-       if orderBy != "" {
-	       switch orderBy {
-	       case "title":
-		       sort.Slice(filteredBooks, func(i, j int) bool {
-			       if orderDir == "desc" {
-				       return filteredBooks[i].Title > filteredBooks[j].Title
-			       }
-			       return filteredBooks[i].Title < filteredBooks[j].Title
-		       })
-	       case "author":
-		       sort.Slice(filteredBooks, func(i, j int) bool {
-			       if orderDir == "desc" {
-				       return filteredBooks[i].Author > filteredBooks[j].Author
-			       }
-			       return filteredBooks[i].Author < filteredBooks[j].Author
-		       })
-	       case "year":
-		       sort.Slice(filteredBooks, func(i, j int) bool {
-			       if orderDir == "desc" {
-				       return filteredBooks[i].Year > filteredBooks[j].Year
-			       }
-			       return filteredBooks[i].Year < filteredBooks[j].Year
-		       })
-	       }
-       }
-
-       utils.WriteSuccessResponse(w, "Books fetched successfully", filteredBooks)
+	// Parse query params for searching, filtering and ordering
+	query := r.URL.Query()
+	lq, err := parseListQuery(query)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	page, err := parsePageRequest(query)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	// Without a search the store filters, sorts and pages the catalog
+	if lq.search == "" {
+		bookQuery, err := lq.bookQuery(page)
+		if err != nil {
+			writeError(w, r, invalidParameter(err))
+			return
+		}
+		result, err := h.store.ListPage(r.Context(), bookQuery)
+		if err != nil {
+			writeStoreError(w, r, err, constants.ErrFetchingBooks)
+			return
+		}
+		pagination := describePage(result.Books, result.Offset, result.Total, page, bookOrder(lq.sortKeys), r.URL)
+		utils.WritePaginatedResponse(w, "Books fetched successfully", result.Books, pagination, result.Facets)
+		return
+	}
+
+	books, scores, err := h.candidateBooks(r.Context(), lq)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	filteredBooks := lq.apply(books)
+	facets := models.CountFacets(books, lq.filter)
+
+	pageBooks, pagination, err := paginate(filteredBooks, page, lq.pageOrder(scores), r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	scoredBooks := make([]*models.ScoredBook, 0, len(pageBooks))
+	for _, book := range pageBooks {
+		scoredBooks = append(scoredBooks, &models.ScoredBook{Book: book, Score: scores[book.ID]})
+	}
+	utils.WritePaginatedResponse(w, "Books fetched successfully", scoredBooks, pagination, facets)
 }
 
 // GetBookByID handles GET /api/books/{id}
-func GetBookByID(w http.ResponseWriter, r *http.Request) {
+func (h *BookHandler) GetBookByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 
@@ -86,18 +94,19 @@ func GetBookByID(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
-	book, err := database.GetBookByID(id)
+	book, err := h.store.Get(r.Context(), id)
 	if err != nil {
-		if err.Error() == constants.ErrBookNotFound {
-			utils.WriteErrorResponse(w, http.StatusNotFound, constants.ErrBookNotFound)
-			return
-		}
-		logrus.WithError(err).Error("Failed to fetch book")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrFetchingBooks)
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	setBookETag(w, book)
+	if header := r.Header.Get("If-None-Match"); header != "" && etagListMatches(header, bookETag(book), true) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -105,34 +114,84 @@ func GetBookByID(w http.ResponseWriter, r *http.Request) {
 }
 
 // CreateBook handles POST /api/books
-func CreateBook(w http.ResponseWriter, r *http.Request) {
+func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	logrus.Info("Creating new book")
 
 	var req models.CreateBookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, constants.ErrInvalidJSON)
+	if apiErr := decodeJSONBody(w, r, &req); apiErr != nil {
+		writeError(w, r, apiErr)
 		return
 	}
 
 	// Validate input
-	if errors := utils.ValidateBook(req); len(errors) > 0 {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, strings.Join(errors, ", "))
+	if fields := utils.Validate(req); len(fields) > 0 {
+		writeError(w, r, models.NewValidationError(fields))
+		return
+	}
+
+	// Refuse a second copy of the same book: a matching ISBN always
+	// conflicts, a matching title and author only unless ?force=true
+	ctx, apiErr := createContext(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	existing, err := h.findISBNOwner(ctx, req)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrCreatingBook)
+		return
+	}
+	if existing != nil {
+		writeDuplicateResponse(w, r, existing.ID)
+		return
+	}
+
+	// The store checks the title and author under its write lock, and the
+	// ISBN may still belong to a book in the trash
+	book, err := h.store.Create(ctx, req)
+	var duplicate *database.DuplicateBookError
+	if errors.As(err, &duplicate) {
+		writeDuplicateResponse(w, r, duplicate.ExistingID)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrCreatingBook)
+		return
+	}
+
+	setBookETag(w, book)
+	utils.WriteJSONResponse(w, http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: constants.MsgBookCreated,
+		Data:    book,
+	})
+}
+
+// GetBookByISBN handles GET /api/books/isbn/{isbn}. Both ISBN-10 and
+// ISBN-13 are accepted, with or without hyphens.
+func (h *BookHandler) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	raw := mux.Vars(r)["isbn"]
+
+	logrus.WithField("isbn", raw).Info("Fetching book by ISBN")
+
+	isbn, err := utils.NormalizeISBN(raw)
+	if err != nil || isbn == "" {
+		writeError(w, r, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidISBN))
 		return
 	}
 
-	book, err := database.CreateBook(req)
+	book, err := h.store.FindByISBN(r.Context(), isbn)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create book")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrCreatingBook)
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	utils.WriteSuccessResponse(w, constants.MsgBookCreated, book)
+	setBookETag(w, book)
+	utils.WriteSuccessResponse(w, constants.MsgBookFetched, book)
 }
 
 // UpdateBook handles PUT /api/books/{id}
-func UpdateBook(w http.ResponseWriter, r *http.Request) {
+func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 
@@ -140,48 +199,147 @@ func UpdateBook(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var req models.UpdateBookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, constants.ErrInvalidJSON)
+	if apiErr := decodeJSONBody(w, r, &req); apiErr != nil {
+		writeError(w, r, apiErr)
 		return
 	}
 
 	// Validate input
-	createReq := models.CreateBookRequest(req)
-	if errors := utils.ValidateBook(createReq); len(errors) > 0 {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, strings.Join(errors, ", "))
+	if fields := utils.Validate(req); len(fields) > 0 {
+		writeError(w, r, models.NewValidationError(fields))
 		return
 	}
 
-	// Check if book exists
-	exists, err := database.BookExists(id)
+	current, err := h.store.Get(r.Context(), id)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to check book existence")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrFetchingBooks)
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
 		return
 	}
 
-	if !exists {
-		utils.WriteErrorResponse(w, http.StatusNotFound, constants.ErrBookNotFound)
+	version, ok := checkIfMatch(r, current)
+	if !ok {
+		writeStoreError(w, r, database.ErrVersionMismatch, constants.ErrInternalServer)
 		return
 	}
 
-	book, err := database.UpdateBook(id, req)
+	book, err := h.store.Update(r.Context(), id, req, version)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to update book")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrBookNotFound)
+		writeStoreError(w, r, err, constants.ErrInternalServer)
 		return
 	}
 
+	setBookETag(w, book)
 	utils.WriteSuccessResponse(w, constants.MsgBookUpdated, book)
 }
 
+// PatchBook handles PATCH /api/books/{id}. The body is an RFC 7396 merge
+// patch (application/merge-patch+json or application/json) or an RFC 6902
+// JSON Patch (application/json-patch+json) applied to the book's editable
+// fields; the merged result goes through the same validation as PUT.
+func (h *BookHandler) PatchBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	logrus.WithField("id", idStr).Info("Patching book")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	mediaType := utils.MergePatchContentType
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			writeError(w, r, errUnsupportedPatch)
+			return
+		}
+	}
+	if mediaType != utils.MergePatchContentType && mediaType != utils.JSONPatchContentType && mediaType != "application/json" {
+		writeError(w, r, errUnsupportedPatch)
+		return
+	}
+
+	patch, apiErr := readJSONBody(w, r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	book, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	version, ok := checkIfMatch(r, book)
+	if !ok {
+		writeStoreError(w, r, database.ErrVersionMismatch, constants.ErrInternalServer)
+		return
+	}
+	// Without If-Match the patch is still applied to the version it was computed from
+	if version == database.AnyVersion {
+		version = book.Version
+	}
+
+	original, err := json.Marshal(models.UpdateBookRequest{
+		Title:       book.Title,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Year:        book.Year,
+		Description: book.Description,
+		Status:      book.Status,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	var patched []byte
+	if mediaType == utils.JSONPatchContentType {
+		patched, err = utils.ApplyJSONPatch(original, patch)
+	} else {
+		patched, err = utils.ApplyMergePatch(original, patch)
+	}
+	if err != nil {
+		writeError(w, r, badRequest(constants.CodeInvalidPatch, err.Error()))
+		return
+	}
+
+	// Only the editable fields may be patched
+	var req models.UpdateBookRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, r, badRequest(constants.CodeInvalidPatch, constants.ErrInvalidPatchResult+": "+err.Error()))
+		return
+	}
+
+	// Validate merged result
+	if fields := utils.Validate(req); len(fields) > 0 {
+		writeError(w, r, models.NewValidationError(fields))
+		return
+	}
+
+	updated, err := h.store.Update(r.Context(), id, req, version)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	setBookETag(w, updated)
+
+	utils.WriteSuccessResponse(w, constants.MsgBookUpdated, updated)
+}
+
 // DeleteBook handles DELETE /api/books/{id}
-func DeleteBook(w http.ResponseWriter, r *http.Request) {
+func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 
@@ -189,27 +347,25 @@ func DeleteBook(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, constants.ErrInvalidID)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
-	// Check if book exists
-	exists, err := database.BookExists(id)
+	current, err := h.store.Get(r.Context(), id)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to check book existence")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrFetchingBooks)
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
 		return
 	}
 
-	if !exists {
-		utils.WriteErrorResponse(w, http.StatusNotFound, constants.ErrBookNotFound)
+	version, ok := checkIfMatch(r, current)
+	if !ok {
+		writeStoreError(w, r, database.ErrVersionMismatch, constants.ErrInternalServer)
 		return
 	}
 
-	err = database.DeleteBook(id)
+	err = h.store.Delete(r.Context(), id, version)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to delete book")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, constants.ErrFetchingBooks)
+		writeStoreError(w, r, err, constants.ErrInternalServer)
 		return
 	}
 
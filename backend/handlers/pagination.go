@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// maxPageLimit caps how many books a single page may contain
+const maxPageLimit = 100
+
+// pageRequest is the parsed form of the limit/offset/cursor query params.
+// A zero limit means the caller did not ask for pagination. cursor is set
+// when the page continues from a keyset cursor instead of an offset.
+type pageRequest struct {
+	limit  int
+	offset int
+	cursor *pageCursor
+}
+
+// pageCursor is the payload behind the opaque cursor handed to clients. It
+// holds the sort key of the last item of the previous page (or, when Before
+// is set, of the first item of the next one) so that a page starts right
+// after that item even if books were added or removed in between. Order
+// names the ordering the key belongs to.
+type pageCursor struct {
+	Order  string          `json:"s"`
+	Key    json.RawMessage `json:"k"`
+	Limit  int             `json:"l"`
+	Before bool            `json:"b,omitempty"`
+}
+
+// pageOrder describes how a paginated list is sorted: spec names the order,
+// key returns the sort key of an item, ending with a unique ID, and seek
+// decodes a key into a function comparing items with it.
+type pageOrder[T any] struct {
+	spec string
+	key  func(item T) interface{}
+	seek func(key json.RawMessage) (func(item T) int, error)
+}
+
+// idOrder orders items by the ascending unique ID returned by id
+func idOrder[T any](spec string, id func(item T) int) pageOrder[T] {
+	return pageOrder[T]{
+		spec: spec,
+		key:  func(item T) interface{} { return id(item) },
+		seek: func(key json.RawMessage) (func(item T) int, error) {
+			var last int
+			if err := json.Unmarshal(key, &last); err != nil {
+				return nil, err
+			}
+			return func(item T) int { return compareInts(id(item), last) }, nil
+		},
+	}
+}
+
+func encodeCursor(c pageCursor) string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	var c pageCursor
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+	if err := json.Unmarshal(payload, &c); err != nil || c.Order == "" || len(c.Key) == 0 || c.Limit < 1 || c.Limit > maxPageLimit {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+	return &c, nil
+}
+
+// parsePageRequest reads limit/offset or cursor from the query string
+func parsePageRequest(query url.Values) (pageRequest, error) {
+	var page pageRequest
+
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, errors.New(constants.ErrInvalidLimit)
+		}
+		page.limit = limit
+	}
+
+	rawOffset := strings.TrimSpace(query.Get("offset"))
+	rawCursor := strings.TrimSpace(query.Get("cursor"))
+	if rawOffset != "" && rawCursor != "" {
+		return page, errors.New(constants.ErrCursorWithOffset)
+	}
+
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil {
+			return page, err
+		}
+		if page.limit == 0 {
+			page.limit = c.Limit
+		}
+		page.cursor = c
+		return page, nil
+	}
+
+	if rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return page, errors.New(constants.ErrInvalidOffset)
+		}
+		page.offset = offset
+		if page.limit == 0 {
+			page.limit = maxPageLimit
+		}
+	}
+
+	return page, nil
+}
+
+// paginate slices items, sorted according to order, as page asks and
+// describes the result, including next/prev links relative to the request
+// URL. Cursor pages are located by the key stored in the cursor rather than
+// by position; a cursor issued for another order is rejected.
+func paginate[T any](items []T, page pageRequest, order pageOrder[T], requestURL *url.URL) ([]T, *models.Pagination, error) {
+	total := len(items)
+	if page.limit == 0 {
+		return items, describePage(items, 0, total, page, order, requestURL), nil
+	}
+
+	start, end := page.offset, 0
+	if page.cursor != nil {
+		compare, err := seekCursor(page, order)
+		if err != nil {
+			return nil, nil, err
+		}
+		if page.cursor.Before {
+			end = sort.Search(total, func(i int) bool { return compare(items[i]) >= 0 })
+			start = end - page.limit
+			if start < 0 {
+				start = 0
+			}
+		} else {
+			start = sort.Search(total, func(i int) bool { return compare(items[i]) > 0 })
+		}
+	}
+	if start > total {
+		start = total
+	}
+	if page.cursor == nil || !page.cursor.Before {
+		end = start + page.limit
+		if end > total {
+			end = total
+		}
+	}
+
+	return items[start:end], describePage(items[start:end], start, total, page, order, requestURL), nil
+}
+
+// seekCursor checks that the cursor of page was issued for order and
+// returns the function comparing items with its key
+func seekCursor[T any](page pageRequest, order pageOrder[T]) (func(item T) int, error) {
+	if page.cursor.Order != order.spec {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+	compare, err := order.seek(page.cursor.Key)
+	if err != nil {
+		return nil, errors.New(constants.ErrInvalidCursor)
+	}
+	return compare, nil
+}
+
+// describePage builds the pagination of pageItems, found at position start
+// among total items sorted according to order, with next/prev cursors and
+// links relative to the request URL.
+func describePage[T any](pageItems []T, start, total int, page pageRequest, order pageOrder[T], requestURL *url.URL) *models.Pagination {
+	pagination := &models.Pagination{Total: total}
+	if page.limit == 0 {
+		return pagination
+	}
+
+	end := start + len(pageItems)
+	pagination.Limit = page.limit
+	pagination.Offset = start
+
+	cursor := func(item T, before bool) string {
+		key, _ := json.Marshal(order.key(item))
+		return encodeCursor(pageCursor{Order: order.spec, Key: key, Limit: page.limit, Before: before})
+	}
+	if end < total && end > start {
+		pagination.NextCursor = cursor(pageItems[len(pageItems)-1], false)
+		pagination.Next = pageLink(requestURL, page, end, pagination.NextCursor)
+	}
+	if start > 0 {
+		prev := start - page.limit
+		if prev < 0 {
+			prev = 0
+		}
+		if len(pageItems) > 0 {
+			pagination.PrevCursor = cursor(pageItems[0], true)
+		}
+		if page.cursor == nil || pagination.PrevCursor != "" {
+			pagination.Prev = pageLink(requestURL, page, prev, pagination.PrevCursor)
+		}
+	}
+
+	return pagination
+}
+
+// pageLink rebuilds the request URL pointing at the neighbouring page, by
+// cursor when the request used one and by offset otherwise, keeping every
+// other query parameter (filters, ordering) untouched.
+func pageLink(requestURL *url.URL, page pageRequest, offset int, cursor string) string {
+	query := requestURL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+	query.Set("limit", strconv.Itoa(page.limit))
+
+	if page.cursor != nil {
+		query.Set("cursor", cursor)
+	} else {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+
+	return requestURL.Path + "?" + query.Encode()
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// shelfOrder pages shelf items, which stores return in book ID order
+var shelfOrder = idOrder("book_id", func(item *models.UserBook) int { return item.BookID })
+
+var errNoCurrentUser = models.NewAPIError(http.StatusUnauthorized, constants.CodeUnauthorized, constants.ErrNoCurrentUser)
+
+// currentUser returns the user behind the request, which is always the
+// authenticated principal. X-Actor only attributes audit entries and never
+// picks a shelf, so unauthenticated requests have none. The user is only
+// created, in a write transaction, the first time it is missing.
+func (h *BookHandler) currentUser(r *http.Request) (*models.User, *models.APIError) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || principal.Subject == "" {
+		return nil, errNoCurrentUser
+	}
+
+	user, err := h.store.GetUser(r.Context(), principal.Subject)
+	if errors.Is(err, database.ErrUserNotFound) {
+		user, err = h.store.EnsureUser(r.Context(), principal.Subject)
+	}
+	if err != nil {
+		return nil, apiError(err, constants.ErrInternalServer)
+	}
+	return user, nil
+}
+
+// GetCurrentUser handles GET /api/me
+func (h *BookHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, apiErr := h.currentUser(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, constants.MsgUserFetched, user)
+}
+
+// GetShelf handles GET /api/me/books and lists the current user's shelf,
+// optionally narrowed to one reading status. The usual pagination
+// parameters apply.
+func (h *BookHandler) GetShelf(w http.ResponseWriter, r *http.Request) {
+	user, apiErr := h.currentUser(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.IsValidStatus(status) {
+		writeError(w, r, badRequest(constants.CodeInvalidParameter, constants.ErrInvalidStatusFilter))
+		return
+	}
+	page, err := parsePageRequest(r.URL.Query())
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"user": user.Username, "status": status}).Info("Fetching shelf")
+
+	items, err := h.store.UserBooks(r.Context(), user.ID)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+	if status != "" {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.Status == status {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	pageItems, pagination, err := paginate(items, page, shelfOrder, r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	utils.WritePaginatedResponse(w, constants.MsgShelfFetched, pageItems, pagination, nil)
+}
+
+// GetShelfBook handles GET /api/me/books/{id}
+func (h *BookHandler) GetShelfBook(w http.ResponseWriter, r *http.Request) {
+	user, id, apiErr := h.shelfTarget(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	item, err := h.store.GetUserBook(r.Context(), user.ID, id)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, constants.MsgShelfBookFetched, item)
+}
+
+// PutShelfBook handles PUT /api/me/books/{id}. It adds a catalog book to
+// the current user's shelf (201) or updates the status, dates and notes of
+// one already there (200).
+func (h *BookHandler) PutShelfBook(w http.ResponseWriter, r *http.Request) {
+	user, id, apiErr := h.shelfTarget(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	var req models.UserBookRequest
+	if apiErr := decodeJSONBody(w, r, &req); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if fields := utils.Validate(req); len(fields) > 0 {
+		writeError(w, r, models.NewValidationError(fields))
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"user": user.Username, "id": id, "status": req.Status}).Info("Shelving book")
+
+	item, created, err := h.store.PutUserBook(r.Context(), user.ID, id, req)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	if created {
+		utils.WriteJSONResponse(w, http.StatusCreated, models.APIResponse{
+			Success: true,
+			Message: constants.MsgShelfBookAdded,
+			Data:    item,
+		})
+		return
+	}
+	utils.WriteSuccessResponse(w, constants.MsgShelfBookUpdated, item)
+}
+
+// DeleteShelfBook handles DELETE /api/me/books/{id}. The book stays in the
+// catalog.
+func (h *BookHandler) DeleteShelfBook(w http.ResponseWriter, r *http.Request) {
+	user, id, apiErr := h.shelfTarget(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"user": user.Username, "id": id}).Info("Removing book from shelf")
+
+	if err := h.store.DeleteUserBook(r.Context(), user.ID, id); err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	utils.WriteSuccessResponse(w, constants.MsgShelfBookRemoved, map[string]int{"id": id})
+}
+
+// shelfTarget resolves the current user and the book ID of a
+// /api/me/books/{id} request
+func (h *BookHandler) shelfTarget(r *http.Request) (*models.User, int, *models.APIError) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, 0, errInvalidID
+	}
+	user, apiErr := h.currentUser(r)
+	if apiErr != nil {
+		return nil, 0, apiErr
+	}
+	return user, id, nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// parseSort reads the sort specification from the query string. The `sort`
+// parameter takes a comma separated list of fields, each optionally prefixed
+// with "-" for descending order (e.g. "author,-year,title"). The legacy
+// orderBy/orderDir pair is still honoured when `sort` is absent. The result
+// always ends with the book ID so that ties are broken deterministically.
+func parseSort(query url.Values) ([]models.SortKey, error) {
+	var keys []models.SortKey
+
+	if raw := strings.TrimSpace(query.Get("sort")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			key := models.SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+			if !models.IsBookSortField(key.Field) {
+				return nil, unknownSortField(key.Field)
+			}
+			keys = append(keys, key)
+		}
+	} else if orderBy := strings.TrimSpace(query.Get("orderBy")); orderBy != "" {
+		if !models.IsBookSortField(orderBy) {
+			return nil, unknownSortField(orderBy)
+		}
+		orderDir := strings.ToLower(strings.TrimSpace(query.Get("orderDir")))
+		if orderDir != "" && orderDir != "asc" && orderDir != "desc" {
+			return nil, errors.New(constants.ErrInvalidOrderDir)
+		}
+		keys = append(keys, models.SortKey{Field: orderBy, Desc: orderDir == "desc"})
+	}
+
+	for _, key := range keys {
+		if key.Field == "id" {
+			return keys, nil
+		}
+	}
+	return append(keys, models.SortKey{Field: "id"}), nil
+}
+
+// hasExplicitSort reports whether the client asked for a specific order
+func hasExplicitSort(query url.Values) bool {
+	return strings.TrimSpace(query.Get("sort")) != "" || strings.TrimSpace(query.Get("orderBy")) != ""
+}
+
+func unknownSortField(field string) error {
+	return fmt.Errorf("%s %q (allowed: %s)", constants.ErrInvalidSortField, field, strings.Join(models.BookSortFields(), ", "))
+}
+
+// sortBooks orders books in place according to keys
+func sortBooks(books []*models.Book, keys []models.SortKey) {
+	sort.SliceStable(books, func(i, j int) bool {
+		return models.CompareBooks(books[i], books[j], keys) < 0
+	})
+}
+
+// bookCursorKey is the cursor key of a book: the fields of the sort keys,
+// which always end with the ID
+type bookCursorKey struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Year      int        `json:"year,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// bookOrder is the pagination order of books sorted by sortBooks with keys
+func bookOrder(keys []models.SortKey) pageOrder[*models.Book] {
+	spec := make([]string, len(keys))
+	for i, key := range keys {
+		spec[i] = key.Field
+		if key.Desc {
+			spec[i] = "-" + key.Field
+		}
+	}
+
+	return pageOrder[*models.Book]{
+		spec: strings.Join(spec, ","),
+		key: func(book *models.Book) interface{} {
+			key := bookCursorKey{ID: book.ID}
+			for _, sk := range keys {
+				switch sk.Field {
+				case "title":
+					key.Title = book.Title
+				case "author":
+					key.Author = book.Author
+				case "year":
+					key.Year = book.Year
+				case "status":
+					key.Status = book.Status
+				case "created_at":
+					key.CreatedAt = &book.CreatedAt
+				case "updated_at":
+					key.UpdatedAt = &book.UpdatedAt
+				}
+			}
+			return key
+		},
+		seek: func(raw json.RawMessage) (func(book *models.Book) int, error) {
+			last, err := decodeBookCursorKey(raw)
+			if err != nil {
+				return nil, err
+			}
+			return func(book *models.Book) int { return models.CompareBooks(book, last, keys) }, nil
+		},
+	}
+}
+
+// decodeBookCursorKey returns a book holding the fields of a cursor key
+func decodeBookCursorKey(raw json.RawMessage) (*models.Book, error) {
+	var key bookCursorKey
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return nil, err
+	}
+	book := &models.Book{ID: key.ID, Title: key.Title, Author: key.Author, Year: key.Year, Status: key.Status}
+	if key.CreatedAt != nil {
+		book.CreatedAt = *key.CreatedAt
+	}
+	if key.UpdatedAt != nil {
+		book.UpdatedAt = *key.UpdatedAt
+	}
+	return book, nil
+}
+
+// relevanceKey is the cursor key of a search hit
+type relevanceKey struct {
+	Score float64 `json:"score"`
+	ID    int     `json:"id"`
+}
+
+// relevanceOrder is the pagination order of search hits, by descending
+// score then ID
+func relevanceOrder(scores map[int]float64) pageOrder[*models.Book] {
+	return pageOrder[*models.Book]{
+		spec: "-score,id",
+		key: func(book *models.Book) interface{} {
+			return relevanceKey{Score: scores[book.ID], ID: book.ID}
+		},
+		seek: func(raw json.RawMessage) (func(book *models.Book) int, error) {
+			var last relevanceKey
+			if err := json.Unmarshal(raw, &last); err != nil {
+				return nil, err
+			}
+			return func(book *models.Book) int {
+				switch score := scores[book.ID]; {
+				case score > last.Score:
+					return -1
+				case score < last.Score:
+					return 1
+				}
+				return compareInts(book.ID, last.ID)
+			}, nil
+		},
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// trashOrder pages trashed books, which stores return in ID order
+var trashOrder = idOrder("id", func(book *models.Book) int { return book.ID })
+
+// GetTrash handles GET /api/books/trash and lists deleted books that have
+// not been purged yet. The usual pagination parameters apply.
+func (h *BookHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("Fetching trash")
+
+	page, err := parsePageRequest(r.URL.Query())
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+
+	books, err := h.store.Trash(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrFetchingBooks)
+		return
+	}
+
+	pageBooks, pagination, err := paginate(books, page, trashOrder, r.URL)
+	if err != nil {
+		writeError(w, r, invalidParameter(err))
+		return
+	}
+	utils.WritePaginatedResponse(w, constants.MsgTrashFetched, pageBooks, pagination, nil)
+}
+
+// RestoreBook handles POST /api/books/{id}/restore
+func (h *BookHandler) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	logrus.WithField("id", idStr).Info("Restoring book")
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	book, err := h.store.Restore(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, constants.ErrInternalServer)
+		return
+	}
+
+	setBookETag(w, book)
+	utils.WriteSuccessResponse(w, constants.MsgBookRestored, book)
+}
@@ -1,11 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"book-library-backend/constants"
 	"book-library-backend/models"
 	"book-library-backend/utils"
 
@@ -19,24 +19,25 @@ func ProcessURL(w http.ResponseWriter, r *http.Request) {
 	var request models.URLRequest
 
 	// Decode JSON request
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		logrus.WithError(err).Error("Failed to decode request body")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+	if apiErr := decodeJSONBody(w, r, &request); apiErr != nil {
+		logrus.WithError(apiErr).Error("Failed to decode request body")
+		writeError(w, r, apiErr)
 		return
 	}
 
 	// Validate input
-	if err := validateURLRequest(request); err != nil {
-		logrus.WithError(err).Error("Request validation failed")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	if fields := utils.Validate(request); len(fields) > 0 {
+		logrus.WithField("errors", models.FieldMessages(fields)).Error("Request validation failed")
+		writeError(w, r, models.NewValidationError(fields))
 		return
 	}
 
 	// Process URL based on operation type
 	processedURL, err := ProcessURLByOperation(request.URL, request.Operation)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to process URL")
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to process URL")
+		apiErr := badRequest(constants.CodeInvalidURL, constants.ErrInvalidURL)
+		apiErr.Err = err
+		writeError(w, r, apiErr)
 		return
 	}
 
@@ -48,41 +49,6 @@ func ProcessURL(w http.ResponseWriter, r *http.Request) {
 	utils.WriteSuccessResponse(w, "URL processed successfully", response)
 }
 
-// validateURLRequest validates the input request
-func validateURLRequest(request models.URLRequest) error {
-	// Check if URL is provided
-	if strings.TrimSpace(request.URL) == "" {
-		return &models.URLError{
-			ErrorType: "validation_error",
-			Message:   "URL is required",
-		}
-	}
-
-	// Validate URL format
-	if _, err := url.Parse(request.URL); err != nil {
-		return &models.URLError{
-			ErrorType: "validation_error",
-			Message:   "Invalid URL format",
-		}
-	}
-
-	// Check if operation is provided and valid
-	validOperations := map[string]bool{
-		"redirection": true,
-		"canonical":   true,
-		"all":         true,
-	}
-
-	if !validOperations[request.Operation] {
-		return &models.URLError{
-			ErrorType: "validation_error",
-			Message:   "Operation must be one of: redirection, canonical, all",
-		}
-	}
-
-	return nil
-}
-
 // processURLByOperation processes the URL based on the operation type
 func ProcessURLByOperation(inputURL, operation string) (string, error) {
 	parsedURL, err := url.Parse(inputURL)
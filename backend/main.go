@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"book-library-backend/auth"
+	"book-library-backend/config"
 	"book-library-backend/database"
 	"book-library-backend/handlers"
 	"book-library-backend/middleware"
@@ -18,21 +22,103 @@ import (
 )
 
 func main() {
+	// Load settings from the defaults, the config file, the environment and
+	// the flags, in increasing order of precedence
+	cfg, opts, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if opts.PrintConfig {
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
 	// Setup logging
-	logrus.SetLevel(logrus.InfoLevel)
-	logrus.SetFormatter(&logrus.JSONFormatter{})
+	level, _ := logrus.ParseLevel(cfg.Log.Level)
+	logrus.SetLevel(level)
+	if cfg.Log.Format == "text" {
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	} else {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	}
+	if opts.File != "" {
+		logrus.WithField("file", opts.File).Info("Loaded configuration file")
+	}
+
+	// Report pending migrations and exit when a dry run is requested. Only
+	// the sqlite driver has a schema to migrate.
+	if cfg.Database.MigrateDryRun {
+		if cfg.Database.Driver != "sqlite" {
+			logrus.WithField("driver", cfg.Database.Driver).Info("Dry run skipped: the driver has no migrations")
+			return
+		}
+		pending, err := database.PlanSQLMigrations(cfg.Database.Path)
+		if err != nil {
+			log.Fatalf("Failed to plan migrations: %v", err)
+		}
+		for _, m := range pending {
+			logrus.WithField("version", m.Version).Infof("Pending migration %s", m.Name)
+		}
+		logrus.Infof("Dry run complete: %d pending migration(s)", len(pending))
+		return
+	}
 
-	// Initialize in-memory database (for demo purposes)
-	if err := database.InitMemoryDB(); err != nil {
+	// Initialize the book store selected by database.driver (memory or sqlite)
+	store, closeStore, err := openStore(cfg.Database.Driver, cfg.Database.Path)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	defer closeStore()
+	bookHandler := handlers.NewBookHandler(store)
+
+	// Purge books that stayed in the trash longer than trash.retention
+	// (0 keeps them forever)
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if cfg.Trash.Retention > 0 {
+		go database.RunTrashPurge(purgeCtx, store, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	}
+
+	// Require an API key or bearer token; refuse to start without credentials
+	// unless auth.disabled is set
+	authenticator, err := cfg.Auth.Authenticator()
+	if err != nil {
+		log.Fatalf("Invalid authentication settings: %v", err)
+	}
+	if authenticator == nil {
+		logrus.Warn("Authentication disabled by auth.disabled: every request is served anonymously")
+	}
+
+	// Answer cross-origin requests from the configured origins
+	cors, err := cfg.CORS.Policy()
+	if err != nil {
+		log.Fatalf("Invalid CORS settings: %v", err)
+	}
+
+	// Throttle each client per route
+	rateLimiter, err := cfg.RateLimit.Limiter()
+	if err != nil {
+		log.Fatalf("Invalid rate limit settings: %v", err)
+	}
 
 	// Setup router
 	router := mux.NewRouter()
 
 	// Add middleware to main router (not subrouter)
-	router.Use(middleware.CORS)
 	router.Use(middleware.Logger)
+	// Failed authentications are counted per remote IP before Authenticate
+	// answers them, other limits apply per authenticated subject after it
+	router.Use(rateLimiter.AuthFailures)
+	router.Use(middleware.Authenticate(authenticator, "/api/health"))
+	router.Use(rateLimiter.Middleware)
+	// Only roles allowed by auth.DefaultPolicy may change the catalog
+	router.Use(middleware.Authorize(authenticator, auth.DefaultPolicy, auth.RouteActions))
+	router.Use(middleware.Actor)
 
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
@@ -41,27 +127,44 @@ func main() {
 	api.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
 
 	// Book routes
-	api.HandleFunc("/books", handlers.GetAllBooks).Methods("GET")
-	api.HandleFunc("/books", handlers.CreateBook).Methods("POST")
-	api.HandleFunc("/books/{id}", handlers.GetBookByID).Methods("GET")
-	api.HandleFunc("/books/{id}", handlers.UpdateBook).Methods("PUT")
-	api.HandleFunc("/books/{id}", handlers.DeleteBook).Methods("DELETE")
+	api.HandleFunc("/books", bookHandler.GetAllBooks).Methods("GET")
+	api.HandleFunc("/books", bookHandler.CreateBook).Methods("POST")
+	api.HandleFunc("/books/batch", bookHandler.BatchBooks).Methods("POST")
+	api.HandleFunc("/books/import", bookHandler.ImportBooks).Methods("POST")
+	api.HandleFunc("/books/export", bookHandler.ExportBooks).Methods("GET")
+	api.HandleFunc("/books/trash", bookHandler.GetTrash).Methods("GET")
+	api.HandleFunc("/books/duplicates", bookHandler.FindDuplicates).Methods("GET")
+	api.HandleFunc("/books/isbn/{isbn}", bookHandler.GetBookByISBN).Methods("GET")
+	api.HandleFunc("/books/{id}", bookHandler.GetBookByID).Methods("GET")
+	api.HandleFunc("/books/{id}", bookHandler.UpdateBook).Methods("PUT")
+	api.HandleFunc("/books/{id}", bookHandler.PatchBook).Methods("PATCH")
+	api.HandleFunc("/books/{id}", bookHandler.DeleteBook).Methods("DELETE")
+	api.HandleFunc("/books/{id}/restore", bookHandler.RestoreBook).Methods("POST")
+	api.HandleFunc("/books/{id}/history", bookHandler.GetBookHistory).Methods("GET")
+	api.HandleFunc("/audit", bookHandler.GetAuditLog).Methods("GET")
+
+	// Personal shelf routes
+	api.HandleFunc("/me", bookHandler.GetCurrentUser).Methods("GET")
+	api.HandleFunc("/me/books", bookHandler.GetShelf).Methods("GET")
+	api.HandleFunc("/me/books/{id}", bookHandler.GetShelfBook).Methods("GET")
+	api.HandleFunc("/me/books/{id}", bookHandler.PutShelfBook).Methods("PUT")
+	api.HandleFunc("/me/books/{id}", bookHandler.DeleteShelfBook).Methods("DELETE")
 
 	// URL processing routes
 	api.HandleFunc("/process-url", handlers.ProcessURL).Methods("POST")
 
 	// Setup server
 	server := &http.Server{
-		Addr:         ":8080",
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:         cfg.Server.Addr,
+		Handler:      cors.Handler(router),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Start server in a goroutine
 	go func() {
-		logrus.Info("Starting server on :8080")
+		logrus.Infof("Starting server on %s", cfg.Server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
@@ -75,7 +178,7 @@ func main() {
 	logrus.Info("Shutting down server...")
 
 	// Create a deadline for the shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	// Attempt graceful shutdown
@@ -85,3 +188,21 @@ func main() {
 
 	logrus.Info("Server exited")
 }
+
+// openStore builds the BookStore for the configured driver and returns a
+// function releasing its resources.
+func openStore(driver, path string) (database.BookStore, func() error, error) {
+	switch driver {
+	case "memory":
+		store, err := database.InitMemoryDB()
+		return store, func() error { return nil }, err
+	case "sqlite":
+		store, err := database.OpenSQLStore(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown database driver %q (expected memory or sqlite)", driver)
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Authenticate middleware resolves the principal of every request with
+// authenticator and stores it in the request context. Requests without valid
+// credentials are rejected with 401, except for publicPaths, which are served
+// to anonymous callers. Only a nil authenticator, meaning authentication was
+// explicitly disabled, lets every request through; one without credentials
+// rejects them all.
+func Authenticate(authenticator *auth.Authenticator, publicPaths ...string) func(http.Handler) http.Handler {
+	public := make(map[string]bool, len(publicPaths))
+	for _, path := range publicPaths {
+		public[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if authenticator == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				if public[r.URL.Path] && errors.Is(err, auth.ErrMissingCredentials) {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				logrus.WithError(err).WithField("url", r.URL.Path).Warn("Authentication failed")
+				challenge := `Bearer realm="elibrary"`
+				if !errors.Is(err, auth.ErrMissingCredentials) {
+					challenge += `, error="invalid_token"`
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				utils.WriteError(w, r, models.NewAPIError(http.StatusUnauthorized, constants.CodeUnauthorized,
+					constants.ErrUnauthorized+": "+err.Error()))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Authorize middleware checks the principal set by Authenticate against
+// policy for the matched mux route, looked up as "METHOD /path/template" in
+// routes. Requests whose principal lacks a role allowed to perform every
+// action of the route are rejected with 403, and so are requests to routes
+// missing from routes unless their method is safe (see auth.IsSafeMethod).
+// Each decision is logged. Like Authenticate, it lets every request through
+// when authenticator is nil.
+func Authorize(authenticator *auth.Authenticator, policy auth.Policy, routes map[string][]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if authenticator == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			template, _ := route.GetPathTemplate()
+			actions, guarded := routes[r.Method+" "+template]
+			if !guarded {
+				if auth.IsSafeMethod(r.Method) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				logrus.WithFields(logrus.Fields{"method": r.Method, "route": template}).Error("Authorization denied: route has no entry in auth.RouteActions")
+				utils.WriteError(w, r, models.NewAPIError(http.StatusForbidden, constants.CodeForbidden,
+					constants.ErrForbidden+": "+fmt.Sprintf(constants.ErrRouteNotGuarded, r.Method, template)))
+				return
+			}
+
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				principal = &models.Principal{}
+			}
+			denied := policy.Denied(principal, actions)
+
+			entry := logrus.WithFields(logrus.Fields{
+				"subject": principal.Subject,
+				"roles":   principal.Roles,
+				"actions": actions,
+				"method":  r.Method,
+				"route":   template,
+				"allowed": denied == "",
+			})
+			if denied != "" {
+				entry.Warn("Authorization denied")
+				message := fmt.Sprintf(constants.ErrActionNotAllowed, denied, strings.Join(policy[denied], ", "))
+				utils.WriteError(w, r, models.NewAPIError(http.StatusForbidden, constants.CodeForbidden,
+					constants.ErrForbidden+": "+message))
+				return
+			}
+			entry.Info("Authorization granted")
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
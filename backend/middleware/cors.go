@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCORSAllowedHeaders are the request headers the API understands
+var DefaultCORSAllowedHeaders = []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", "X-Actor", "X-API-Key"}
+
+// DefaultCORSExposedHeaders are the response headers scripts may read
+var DefaultCORSExposedHeaders = []string{"ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// corsMethods are the methods a preflight may ask about
+var corsMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// CORSConfig configures a CORS policy
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins such as https://example.com and
+	// patterns such as https://*.example.com or http://localhost:*, where *
+	// matches any run of characters but "/". A lone * allows any origin.
+	AllowedOrigins []string
+	// AllowCredentials lets browsers send cookies and authorization headers.
+	// It cannot be combined with a lone * origin.
+	AllowCredentials bool
+	// AllowedHeaders lists the request headers preflights may ask for
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers scripts may read
+	ExposedHeaders []string
+	// MaxAge is how long browsers may cache a preflight answer
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and decorates responses to allowed
+// origins. The methods allowed on a path are those of the mux routes
+// matching it.
+type CORS struct {
+	config    CORSConfig
+	anyOrigin bool
+	origins   map[string]bool
+	patterns  []string
+	headers   map[string]bool
+}
+
+// NewCORS validates config and returns its policy
+func NewCORS(config CORSConfig) (*CORS, error) {
+	c := &CORS{
+		config:  config,
+		origins: make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		switch {
+		case origin == "":
+		case origin == "*":
+			c.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			if _, err := path.Match(origin, ""); err != nil {
+				return nil, fmt.Errorf("invalid CORS origin pattern %q: %v", origin, err)
+			}
+			c.patterns = append(c.patterns, strings.ToLower(origin))
+		default:
+			c.origins[strings.ToLower(origin)] = true
+		}
+	}
+	if c.anyOrigin && config.AllowCredentials {
+		return nil, fmt.Errorf("CORS credentials cannot be allowed for any origin (*); list the origins instead")
+	}
+	for _, header := range config.AllowedHeaders {
+		c.headers[http.CanonicalHeaderKey(strings.TrimSpace(header))] = true
+	}
+	return c, nil
+}
+
+// AllowsOrigin reports whether origin matches the allowlist
+func (c *CORS) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	if c.anyOrigin || c.origins[origin] {
+		return true
+	}
+	for _, pattern := range c.patterns {
+		if matched, _ := path.Match(pattern, origin); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler wraps router so that preflights are answered before routing and
+// responses to allowed origins carry the CORS headers. It must wrap the
+// router rather than be added with Use, as mux does not run middleware for
+// the OPTIONS requests no route accepts.
+func (c *CORS) Handler(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			router.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			c.preflight(w, r, router, origin)
+			return
+		}
+
+		if c.AllowsOrigin(origin) {
+			c.allowOrigin(w, origin)
+			if len(c.config.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposedHeaders, ", "))
+			}
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+// preflight answers an OPTIONS request announcing a cross-origin request,
+// rejecting it with 403 when the origin, method or headers are not allowed
+func (c *CORS) preflight(w http.ResponseWriter, r *http.Request, router *mux.Router, origin string) {
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	methods := routeMethods(router, r)
+	if len(methods) == 0 {
+		router.ServeHTTP(w, r)
+		return
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(r.Header.Get("Access-Control-Request-Method")))
+	headers := requestedHeaders(r)
+	reject := func(reason string) {
+		logrus.WithFields(logrus.Fields{"origin": origin, "method": method, "url": r.URL.Path}).Warn("CORS preflight rejected: " + reason)
+		utils.WriteError(w, r, models.NewAPIError(http.StatusForbidden, constants.CodeForbidden, reason))
+	}
+
+	if !c.AllowsOrigin(origin) {
+		reject(constants.ErrCORSOrigin)
+		return
+	}
+	if !containsString(methods, method) {
+		reject(fmt.Sprintf(constants.ErrCORSMethod, method))
+		return
+	}
+	for _, header := range headers {
+		if !c.headers[header] {
+			reject(fmt.Sprintf(constants.ErrCORSHeader, header))
+			return
+		}
+	}
+
+	c.allowOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(headers) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if c.config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CORS) allowOrigin(w http.ResponseWriter, origin string) {
+	if c.anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.config.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// routeMethods lists the methods router has a route for at the path of r
+func routeMethods(router *mux.Router, r *http.Request) []string {
+	var methods []string
+	for _, method := range corsMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		var match mux.RouteMatch
+		if router.Match(probe, &match) && match.MatchErr == nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// requestedHeaders returns the canonical names listed in the
+// Access-Control-Request-Headers of a preflight
+func requestedHeaders(r *http.Request) []string {
+	var headers []string
+	for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, http.CanonicalHeaderKey(header))
+			}
+		}
+	}
+	return headers
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,8 +2,15 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,18 +32,22 @@ func Logger(next http.Handler) http.Handler {
 	})
 }
 
-// CORS middleware
-func CORS(next http.Handler) http.Handler {
+// Actor middleware attributes audited changes to the authenticated
+// principal. Unauthenticated requests are attributed to the X-Actor request
+// header, or recorded as anonymous without it. An X-Actor naming someone
+// other than the principal is rejected with 403.
+func Actor(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Enabled CORS almost for everything.
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
+		actor := strings.TrimSpace(r.Header.Get("X-Actor"))
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+			if actor != "" && actor != principal.Subject {
+				utils.WriteError(w, r, models.NewAPIError(http.StatusForbidden, constants.CodeForbidden, constants.ErrActorMismatch))
+				return
+			}
+			actor = principal.Subject
+		}
+		if actor != "" {
+			r = r.WithContext(database.WithActor(r.Context(), actor))
 		}
 
 		next.ServeHTTP(w, r)
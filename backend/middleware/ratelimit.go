@@ -0,0 +1,353 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit is a token bucket holding up to Burst requests and refilled at
+// Rate requests per second. The zero value means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether l limits anything
+func (l RateLimit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// ParseRateLimit reads a limit written as N/s, N/m or N/h, allowing bursts
+// of N requests. "off" disables limiting.
+func ParseRateLimit(spec string) (RateLimit, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "off" {
+		return RateLimit{}, nil
+	}
+
+	count, unit, ok := strings.Cut(spec, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q (expected N/s, N/m, N/h or off)", spec)
+	}
+	periods := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+	period, ok := periods[strings.TrimSpace(unit)]
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q (expected N/s, N/m, N/h or off)", spec)
+	}
+	return RateLimit{Rate: float64(n) / period.Seconds(), Burst: n}, nil
+}
+
+// ParseRouteRateLimits reads a comma-separated list of
+// "METHOD /path/template=limit" entries, see ParseRateLimit
+func ParseRouteRateLimits(spec string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		route, limitSpec, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || method == "" || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return nil, fmt.Errorf("invalid route rate limit %q (expected METHOD /path=limit)", entry)
+		}
+		limit, err := ParseRateLimit(limitSpec)
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = limit
+	}
+	return limits, nil
+}
+
+// RateLimiterConfig configures a RateLimiter
+type RateLimiterConfig struct {
+	// Default applies to every route missing from Routes
+	Default RateLimit
+	// Routes overrides Default for the "METHOD /path/template" of mux routes
+	Routes map[string]RateLimit
+	// AuthFailures limits the failed authentications of each remote IP, see
+	// RateLimiter.AuthFailures
+	AuthFailures RateLimit
+	// IdleTimeout is how long an unused bucket is kept; it defaults to ten
+	// minutes
+	IdleTimeout time.Duration
+	// MaxBuckets caps the buckets held in memory; it defaults to
+	// DefaultMaxRateLimitBuckets. New clients are throttled while every
+	// bucket is in use.
+	MaxBuckets int
+	// Now replaces time.Now, for tests
+	Now func() time.Time
+}
+
+// DefaultMaxRateLimitBuckets is the default RateLimiterConfig.MaxBuckets
+const DefaultMaxRateLimitBuckets = 100000
+
+// RateLimiter throttles each client separately on every route. Clients are
+// told apart by the subject of the principal set by Authenticate, or by their
+// remote IP without one, so Middleware must run after Authenticate while
+// AuthFailures runs before it.
+type RateLimiter struct {
+	config    RateLimiterConfig
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// lastFullSweep is when the buckets were last swept for lack of room
+	lastFullSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled completely
+	full time.Time
+}
+
+// NewRateLimiter returns a RateLimiter for config
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 10 * time.Minute
+	}
+	if config.MaxBuckets <= 0 {
+		config.MaxBuckets = DefaultMaxRateLimitBuckets
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &RateLimiter{
+		config:    config,
+		buckets:   make(map[string]*bucket),
+		lastSweep: config.Now(),
+	}
+}
+
+// Buckets reports how many buckets are held in memory
+func (l *RateLimiter) Buckets() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.buckets)
+}
+
+// Middleware rejects requests over the limit of their route with 429 and
+// reports the state of the bucket in X-RateLimit-Limit, X-RateLimit-Remaining
+// and X-RateLimit-Reset (seconds until the bucket is full again).
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = r.Method + " " + template
+			}
+		}
+		limit, ok := l.config.Routes[route]
+		if !ok {
+			limit = l.config.Default
+		}
+		if !limit.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, remaining, retryAfter, reset := l.take(clientKey(r)+"|"+route, limit)
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+		if !allowed {
+			logrus.WithFields(logrus.Fields{"route": route, "remote_ip": r.RemoteAddr}).Warn("Rate limit exceeded")
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			utils.WriteError(w, r, models.NewAPIError(http.StatusTooManyRequests, constants.CodeRateLimited, constants.ErrRateLimited))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AuthFailures throttles credential guessing: it must run before
+// Authenticate, which answers bad credentials before Middleware sees them.
+// Every 401 served to a remote IP spends a token of its AuthFailures bucket,
+// and once the bucket is empty requests from that IP get 429 until it
+// refills. Successful requests cost nothing.
+func (l *RateLimiter) AuthFailures(next http.Handler) http.Handler {
+	limit := l.config.AuthFailures
+	if !limit.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "auth-failures|ip:" + remoteIP(r)
+		if allowed, retryAfter := l.peek(key, limit); !allowed {
+			logrus.WithField("remote_ip", r.RemoteAddr).Warn("Too many failed authentications")
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			utils.WriteError(w, r, models.NewAPIError(http.StatusTooManyRequests, constants.CodeRateLimited, constants.ErrRateLimited))
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == http.StatusUnauthorized {
+			l.take(key, limit)
+		}
+	})
+}
+
+// take refills the bucket under key and spends a token from it when one is
+// left. It returns the tokens remaining, the wait until the next token and
+// the wait until the bucket is full.
+func (l *RateLimiter) take(key string, limit RateLimit) (bool, int, time.Duration, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.config.Now()
+	b := l.refillLocked(key, limit, now)
+	if b == nil {
+		return false, 0, time.Second, time.Second
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	retryAfter := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	reset := time.Duration((float64(limit.Burst) - b.tokens) / limit.Rate * float64(time.Second))
+	b.full = now.Add(reset)
+	return allowed, int(b.tokens), retryAfter, reset
+}
+
+// peek reports whether the bucket under key holds a token without spending
+// it, and otherwise the wait until the next token
+func (l *RateLimiter) peek(key string, limit RateLimit) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	b, exists := l.buckets[key]
+	if !exists {
+		return true, 0
+	}
+	tokens := math.Min(float64(limit.Burst), b.tokens+l.config.Now().Sub(b.last).Seconds()*limit.Rate)
+	if tokens >= 1 {
+		return true, 0
+	}
+	return false, time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+}
+
+// refillLocked returns the bucket under key, created full when missing and
+// refilled up to now, or nil when there is no room for a new bucket.
+// l.mutex must be held.
+func (l *RateLimiter) refillLocked(key string, limit RateLimit, now time.Time) *bucket {
+	l.sweepLocked(now)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		if !l.makeRoomLocked(now) {
+			logrus.WithField("buckets", len(l.buckets)).Error("Rate limiter full, throttling new clients")
+			return nil
+		}
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	return b
+}
+
+// sweepLocked drops the buckets left unused for longer than the idle timeout,
+// at most once per timeout; l.mutex must be held. Only buckets that have
+// refilled completely are dropped, so eviction never hands a client more
+// requests than its limit allows.
+func (l *RateLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < l.config.IdleTimeout {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.config.IdleTimeout && !now.Before(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// makeRoomLocked reports whether a new bucket fits under MaxBuckets, first
+// dropping the buckets that have refilled completely, as a new bucket would
+// start full anyway. It scans the buckets at most once a second so that a
+// full limiter stays cheap to hit. l.mutex must be held.
+func (l *RateLimiter) makeRoomLocked(now time.Time) bool {
+	if len(l.buckets) < l.config.MaxBuckets {
+		return true
+	}
+	if now.Sub(l.lastFullSweep) < time.Second {
+		return false
+	}
+	for key, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastFullSweep = now
+	return len(l.buckets) < l.config.MaxBuckets
+}
+
+// clientKey identifies the client of r by the subject Authenticate verified,
+// or by its remote IP. Unverified credentials and forwarding headers are
+// ignored as they are chosen by the client.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Subject != "" {
+		return "subject:" + principal.Subject
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// remoteIP returns the host part of r.RemoteAddr
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
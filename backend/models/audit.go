@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Audited actions
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// AnonymousActor is recorded when a change is made without a known actor,
+// SystemActor for changes made by background jobs
+const (
+	AnonymousActor = "anonymous"
+	SystemActor    = "system"
+)
+
+// FieldChange is one field of a book changed by an audited action. Old is
+// null for creates, New is null when a field is cleared.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// AuditEntry records who changed which book, when, and how
+type AuditEntry struct {
+	ID        int           `json:"id"`
+	BookID    int           `json:"book_id"`
+	Action    string        `json:"action"`
+	Actor     string        `json:"actor"`
+	Timestamp time.Time     `json:"timestamp"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything. Since is
+// inclusive and Until exclusive.
+type AuditFilter struct {
+	BookID int
+	Actor  string
+	Action string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Matches reports whether entry passes every filter
+func (f AuditFilter) Matches(entry *AuditEntry) bool {
+	if f.BookID != 0 && entry.BookID != f.BookID {
+		return false
+	}
+	if f.Actor != "" && entry.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
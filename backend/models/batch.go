@@ -0,0 +1,30 @@
+package models
+
+// Batch operation kinds
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one entry of a POST /api/books/batch request. Book is
+// required for create and update, ID for update and delete. A non-zero
+// Version makes update/delete conditional, like If-Match.
+type BatchOperation struct {
+	Op      string            `json:"op"`
+	ID      int               `json:"id,omitempty"`
+	Version int               `json:"version,omitempty"`
+	Book    CreateBookRequest `json:"book"`
+}
+
+// BatchItemResult reports the outcome of one batch operation
+type BatchItemResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	Status int          `json:"status"`
+	ID     int          `json:"id,omitempty"`
+	Book   *Book        `json:"book,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
@@ -4,38 +4,94 @@ import "time"
 
 // Book represents a book in the library
 type Book struct {
-	ID          int       `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Author      string    `json:"author" db:"author"`
-	Year        int       `json:"year" db:"year"`
-	Description string    `json:"description" db:"description"`
-	Status      string    `json:"status" db:"status"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          int    `json:"id" db:"id"`
+	Title       string `json:"title" db:"title"`
+	Author      string `json:"author" db:"author"`
+	ISBN        string `json:"isbn,omitempty" db:"isbn"`
+	Year        int    `json:"year" db:"year"`
+	Description string `json:"description" db:"description"`
+	// Status is the deprecated library-wide reading status, shared by every
+	// user. Each user's own reading status lives on their shelf (UserBook);
+	// the two are independent.
+	Status    string    `json:"status" db:"status"`
+	Version   int       `json:"version" db:"version"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// DeletedAt is set while the book sits in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// DefaultCatalogStatus is stored when a book is created without a status
+const DefaultCatalogStatus = "to-read"
+
+// ValidStatuses lists the reading states a book can be in
+var ValidStatuses = []string{"to-read", "reading", "read"}
+
+// IsValidStatus reports whether status is one of ValidStatuses
+func IsValidStatus(status string) bool {
+	for _, valid := range ValidStatuses {
+		if status == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ScoredBook is a book matched by a full-text search with its relevance score
+type ScoredBook struct {
+	*Book
+	Score float64 `json:"score"`
 }
 
 // CreateBookRequest represents the request body for creating a book
 type CreateBookRequest struct {
 	Title       string `json:"title" validate:"required"`
 	Author      string `json:"author" validate:"required"`
-	Year        int    `json:"year" validate:"required,min=1000,max=2100"`
+	ISBN        string `json:"isbn,omitempty" validate:"omitempty,isbn"`
+	Year        int    `json:"year" validate:"required,min=1000,notfuture"`
 	Description string `json:"description"`
-	Status      string `json:"status" validate:"required,oneof=to-read reading read"`
+	// Status is deprecated, see Book.Status; it defaults to
+	// DefaultCatalogStatus
+	Status string `json:"status,omitempty" validate:"omitempty,oneof=to-read reading read"`
 }
 
 // UpdateBookRequest represents the request body for updating a book
 type UpdateBookRequest struct {
 	Title       string `json:"title" validate:"required"`
 	Author      string `json:"author" validate:"required"`
-	Year        int    `json:"year" validate:"required,min=1000,max=2100"`
+	ISBN        string `json:"isbn,omitempty" validate:"omitempty,isbn"`
+	Year        int    `json:"year" validate:"required,min=1000,notfuture"`
 	Description string `json:"description"`
-	Status      string `json:"status" validate:"required,oneof=to-read reading read"`
+	// Status is deprecated, see Book.Status; it is left unchanged when empty
+	Status string `json:"status,omitempty" validate:"omitempty,oneof=to-read reading read"`
 }
 
 // APIResponse represents a standard API response
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success    bool         `json:"success"`
+	Message    string       `json:"message"`
+	Data       interface{}  `json:"data,omitempty"`
+	Pagination *Pagination  `json:"pagination,omitempty"`
+	Facets     *Facets      `json:"facets,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Code       string       `json:"code,omitempty"`
+	Errors     []FieldError `json:"errors,omitempty"`
+}
+
+// Facets holds per-value counts used to render listing filter chips. Each
+// facet ignores its own filter, so selecting a chip never hides its siblings.
+type Facets struct {
+	Status map[string]int `json:"status"`
+	Decade map[string]int `json:"decade"`
+}
+
+// Pagination describes the page returned by a listing endpoint
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
 }
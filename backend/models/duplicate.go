@@ -0,0 +1,8 @@
+package models
+
+// DuplicateGroup is a set of books that look like the same title. Score is
+// the lowest pairwise similarity that links the group together.
+type DuplicateGroup struct {
+	Score float64 `json:"score"`
+	Books []*Book `json:"books"`
+}
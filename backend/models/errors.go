@@ -0,0 +1,83 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+
+	"book-library-backend/constants"
+)
+
+// FieldError describes why one request field was rejected. Code names the
+// failed rule (required, min, oneof, ...).
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BodyPosition locates a decoding error in a request body. Offset counts
+// bytes from the start of the body; Line and Column start at 1.
+type BodyPosition struct {
+	Offset int64 `json:"offset"`
+	Line   int   `json:"line"`
+	Column int   `json:"column"`
+}
+
+// APIError is an error reported to clients with an HTTP status and a stable
+// machine-readable code. Data, when set, is returned alongside the error.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+	Data    interface{}
+	Err     error
+}
+
+// NewAPIError returns an APIError without field details
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// NewValidationError reports rejected request fields as a 400. The message
+// lists every field message for clients that only read the error string.
+func NewValidationError(fields []FieldError) *APIError {
+	return &APIError{
+		Status:  http.StatusBadRequest,
+		Code:    constants.CodeValidationFailed,
+		Message: strings.Join(FieldMessages(fields), ", "),
+		Fields:  fields,
+	}
+}
+
+// FieldMessages returns the message of every field error
+func FieldMessages(fields []FieldError) []string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return messages
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying cause, if any
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// ProblemDetails is the RFC 7807 application/problem+json error body, with
+// the code, field errors and data as extension members
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Data     interface{}  `json:"data,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Import row outcomes
+const (
+	ImportCreated  = "created"
+	ImportSkipped  = "skipped"
+	ImportRejected = "rejected"
+)
+
+// ImportRowResult reports what happened to one imported row. For CSV input
+// Row is the line number (the header is row 1); for JSON it is the 1-based
+// position in the array.
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Status string   `json:"status"`
+	ID     int      `json:"id,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReport summarises a POST /api/books/import request
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Created  int               `json:"created"`
+	Skipped  int               `json:"skipped"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
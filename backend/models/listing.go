@@ -0,0 +1,181 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Facet names, used to leave a facet's own filter out when counting it
+const (
+	FacetNone   = ""
+	FacetStatus = "status"
+	FacetDecade = "decade"
+)
+
+// BookFilter selects books; zero fields match everything. Title and Author
+// are lowercased substrings, Statuses the accepted statuses.
+type BookFilter struct {
+	Title        string
+	Author       string
+	Statuses     map[string]bool
+	YearFrom     int
+	YearTo       int
+	CreatedAfter time.Time
+	UpdatedSince time.Time
+}
+
+// Matches reports whether book passes every filter
+func (f BookFilter) Matches(book *Book) bool {
+	return f.MatchesExcept(book, FacetNone)
+}
+
+// MatchesExcept applies every filter except the one backing facet, so that a
+// facet's counts show what selecting another value would return.
+func (f BookFilter) MatchesExcept(book *Book, facet string) bool {
+	if f.Title != "" && !strings.Contains(strings.ToLower(book.Title), f.Title) {
+		return false
+	}
+	if f.Author != "" && !strings.Contains(strings.ToLower(book.Author), f.Author) {
+		return false
+	}
+	if facet != FacetStatus && f.Statuses != nil && !f.Statuses[book.Status] {
+		return false
+	}
+	if facet != FacetDecade {
+		if f.YearFrom != 0 && book.Year < f.YearFrom {
+			return false
+		}
+		if f.YearTo != 0 && book.Year > f.YearTo {
+			return false
+		}
+	}
+	if !f.CreatedAfter.IsZero() && !book.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.UpdatedSince.IsZero() && book.UpdatedAt.Before(f.UpdatedSince) {
+		return false
+	}
+	return true
+}
+
+// NewFacets returns facets with every status counted as zero
+func NewFacets() *Facets {
+	facets := &Facets{
+		Status: make(map[string]int),
+		Decade: make(map[string]int),
+	}
+	for _, status := range ValidStatuses {
+		facets.Status[status] = 0
+	}
+	return facets
+}
+
+// CountFacets counts books per status and per decade under f
+func CountFacets(books []*Book, f BookFilter) *Facets {
+	facets := NewFacets()
+	for _, book := range books {
+		if f.MatchesExcept(book, FacetStatus) {
+			facets.Status[book.Status]++
+		}
+		if f.MatchesExcept(book, FacetDecade) {
+			facets.Decade[DecadeFacet(book.Year)]++
+		}
+	}
+	return facets
+}
+
+// DecadeFacet is the decade facet value of year, e.g. "1960s"
+func DecadeFacet(year int) string {
+	return fmt.Sprintf("%ds", year/10*10)
+}
+
+// SortKey is one entry of a sort specification such as "-year"
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// bookComparators holds every field books can be sorted by
+var bookComparators = map[string]func(a, b *Book) int{
+	"id":         func(a, b *Book) int { return compareInts(a.ID, b.ID) },
+	"title":      func(a, b *Book) int { return compareFold(a.Title, b.Title) },
+	"author":     func(a, b *Book) int { return compareFold(a.Author, b.Author) },
+	"year":       func(a, b *Book) int { return compareInts(a.Year, b.Year) },
+	"status":     func(a, b *Book) int { return strings.Compare(a.Status, b.Status) },
+	"created_at": func(a, b *Book) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *Book) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// IsBookSortField reports whether books can be sorted by field
+func IsBookSortField(field string) bool {
+	_, ok := bookComparators[field]
+	return ok
+}
+
+// BookSortFields lists the fields books can be sorted by, alphabetically
+func BookSortFields() []string {
+	fields := make([]string, 0, len(bookComparators))
+	for name := range bookComparators {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CompareBooks orders a and b according to keys: negative when a comes
+// first, positive when b does and zero when keys do not tell them apart.
+// Titles and authors compare case-insensitively.
+func CompareBooks(a, b *Book, keys []SortKey) int {
+	for _, key := range keys {
+		if c := bookComparators[key.Field](a, b); c != 0 {
+			if key.Desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFold compares case-insensitively, falling back to a byte-wise
+// comparison so that "abc" and "ABC" still have a fixed order.
+func compareFold(a, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// BookQuery selects one page of the books matching Filter, ordered by Sort,
+// which ends with the ID. A zero Limit returns every match. With Seek set
+// the page starts right after that book, or ends right before it when Before
+// is set, instead of at Offset; Seek only needs the sorted fields.
+type BookQuery struct {
+	Filter BookFilter
+	Sort   []SortKey
+	Limit  int
+	Offset int
+	Seek   *Book
+	Before bool
+}
+
+// BookPage is the result of a BookQuery: the books of the page, the position
+// of the first one among the Total matches and the facet counts of Filter.
+type BookPage struct {
+	Books  []*Book
+	Offset int
+	Total  int
+	Facets *Facets
+}
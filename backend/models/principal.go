@@ -0,0 +1,14 @@
+package models
+
+// Authentication methods a Principal can come from
+const (
+	AuthAPIKey = "api_key"
+	AuthJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles,omitempty"`
+	Method  string   `json:"method"`
+}
@@ -10,14 +10,3 @@ type URLRequest struct {
 type URLResponse struct {
 	ProcessedURL string `json:"processed_url"`
 }
-
-// URLError represents error response structure
-type URLError struct {
-	ErrorType string `json:"error"`
-	Message   string `json:"message"`
-}
-
-// Error implements the error interface
-func (e *URLError) Error() string {
-	return e.Message
-}
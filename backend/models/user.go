@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"book-library-backend/constants"
+)
+
+// User is a reader with a personal shelf. Users are created the first time
+// their username, the authenticated subject, reaches the shelf endpoints.
+type User struct {
+	ID        int       `json:"id" db:"id"`
+	Username  string    `json:"username" db:"username"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// UserBook is a catalog book on a user's shelf together with that user's
+// own reading status, dates and notes
+type UserBook struct {
+	UserID     int        `json:"user_id" db:"user_id"`
+	BookID     int        `json:"book_id" db:"book_id"`
+	Status     string     `json:"status" db:"status"`
+	StartedAt  *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	Notes      string     `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	// Book is the catalog entry the shelf item refers to
+	Book *Book `json:"book,omitempty"`
+}
+
+// UserBookRequest represents the request body for putting a book on a shelf.
+// Omitted dates keep their current value; moving to reading or read stamps
+// started_at or finished_at when they are still unset.
+type UserBookRequest struct {
+	Status     string     `json:"status" validate:"required,oneof=to-read reading read"`
+	StartedAt  *time.Time `json:"started_at,omitempty" validate:"omitempty,notfuture"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" validate:"omitempty,notfuture"`
+	Notes      string     `json:"notes,omitempty" validate:"max=2000"`
+}
+
+// Apply returns the shelf item resulting from req on top of current, which
+// is nil when the book is not on the shelf yet. It fails with a validation
+// error when the resulting finished_at is earlier than started_at.
+func (req UserBookRequest) Apply(current *UserBook, userID, bookID int, now time.Time) (*UserBook, error) {
+	item := &UserBook{UserID: userID, BookID: bookID, CreatedAt: now}
+	if current != nil {
+		*item = *current
+	}
+	item.Status = req.Status
+	item.Notes = req.Notes
+	item.UpdatedAt = now
+
+	if req.StartedAt != nil {
+		item.StartedAt = req.StartedAt
+	} else if item.StartedAt == nil && req.Status != "to-read" {
+		item.StartedAt = &now
+	}
+	if req.FinishedAt != nil {
+		item.FinishedAt = req.FinishedAt
+	} else if item.FinishedAt == nil && req.Status == "read" {
+		item.FinishedAt = &now
+	}
+
+	if item.StartedAt != nil && item.FinishedAt != nil && item.FinishedAt.Before(*item.StartedAt) {
+		return nil, NewValidationError([]FieldError{{
+			Field:   "finished_at",
+			Code:    constants.FieldNotBefore,
+			Message: fmt.Sprintf(constants.ErrFieldNotBefore, "finished_at", "started_at"),
+		}})
+	}
+	return item, nil
+}
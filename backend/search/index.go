@@ -0,0 +1,153 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Field weights: a hit in the title counts more than one in the description
+const (
+	titleWeight       = 3.0
+	authorWeight      = 2.0
+	descriptionWeight = 1.0
+)
+
+// Document is the searchable text of a single book
+type Document struct {
+	Title       string
+	Author      string
+	Description string
+}
+
+// Hit is a matching document and its relevance score
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index is an inverted index from terms to the documents containing them.
+// It is safe for concurrent use.
+type Index struct {
+	mutex    sync.RWMutex
+	postings map[string]map[int]float64 // term -> doc ID -> weighted term frequency
+	docs     map[int][]string           // doc ID -> distinct terms, for removal
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]float64),
+		docs:     make(map[int][]string),
+	}
+}
+
+// Put adds or replaces the document stored under id.
+func (idx *Index) Put(id int, doc Document) {
+	weights := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{doc.Title, titleWeight},
+		{doc.Author, authorWeight},
+		{doc.Description, descriptionWeight},
+	} {
+		for _, term := range Tokenize(field.text) {
+			weights[term] += field.weight
+		}
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(id)
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int]float64)
+		}
+		idx.postings[term][id] = weight
+		terms = append(terms, term)
+	}
+	idx.docs[id] = terms
+}
+
+// Remove drops the document stored under id, if any.
+func (idx *Index) Remove(id int) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id int) {
+	for _, term := range idx.docs[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search returns the documents containing every term of query, ranked by
+// a TF-IDF score (highest first, then by ID). A query without any
+// searchable terms matches nothing.
+func (idx *Index) Search(query string) []Hit {
+	terms := uniqueTerms(Tokenize(query))
+	if len(terms) == 0 {
+		return []Hit{}
+	}
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	total := float64(len(idx.docs))
+	scores := make(map[int]float64)
+	for i, term := range terms {
+		posting := idx.postings[term]
+		if len(posting) == 0 {
+			return []Hit{}
+		}
+		idf := math.Log(1 + total/float64(len(posting)))
+
+		if i == 0 {
+			for id, weight := range posting {
+				scores[id] = weight * idf
+			}
+			continue
+		}
+		for id := range scores {
+			weight, ok := posting[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += weight * idf
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,44 @@
+package search
+
+// Similarity compares two strings after Normalize and returns a score
+// between 0 (nothing in common) and 1 (equal), based on the edit distance
+// relative to the longer string.
+func Similarity(a, b string) float64 {
+	return RuneSimilarity([]rune(Normalize(a)), []rune(Normalize(b)))
+}
+
+// RuneSimilarity is Similarity for texts already passed through Normalize,
+// so that callers comparing many pairs normalize each text only once.
+func RuneSimilarity(ra, rb []rune) float64 {
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein counts the single-rune insertions, deletions and substitutions
+// turning a into b, keeping only two rows of the distance matrix.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func auditEntries(t *testing.T, resp models.APIResponse) []models.AuditEntry {
+	t.Helper()
+	raw, _ := json.Marshal(resp.Data)
+	var entries []models.AuditEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		t.Fatalf("Failed to decode audit entries: %v", err)
+	}
+	return entries
+}
+
+func auditActions(entries []models.AuditEntry) string {
+	actions := make([]string, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+	}
+	return strings.Join(actions, ",")
+}
+
+func TestBookHistoryRecordsActorAndDiff(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, before := doRequest(t, router, "GET", "/api/books/2", "")
+	description := before.Data.(map[string]interface{})["description"].(string)
+	body := `{"title":"1984","author":"George Orwell","year":1949,"status":"read","description":"` + description + `"}`
+	if rec, _ := doRequestWithHeaders(t, router, "PUT", "/api/books/2", body, map[string]string{"Content-Type": "application/json", "X-Actor": "alice"}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec, _ := doRequestWithHeaders(t, router, "DELETE", "/api/books/2", "", map[string]string{"X-Actor": "bob"}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	rec, resp := doRequest(t, router, "GET", "/api/books/2/history", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	entries := auditEntries(t, resp)
+	if auditActions(entries) != "update,delete" || entries[0].Actor != "alice" || entries[1].Actor != "bob" {
+		t.Fatalf("Unexpected history %+v", entries)
+	}
+	change := entries[0].Changes
+	if len(change) != 1 || change[0].Field != "status" || change[0].Old != "reading" || change[0].New != "read" {
+		t.Errorf("Expected only the status change, got %+v", change)
+	}
+	if len(entries[1].Changes) != 1 || entries[1].Changes[0].Field != "deleted_at" || entries[1].Changes[0].Old != nil {
+		t.Errorf("Expected deleted_at change on delete, got %+v", entries[1].Changes)
+	}
+	t.Logf("\n📜 History of book 2: %s", auditActions(entries))
+}
+
+func TestAuditLogFilters(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	create := `{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read"}`
+	doRequestWithHeaders(t, router, "POST", "/api/books", create, map[string]string{"Content-Type": "application/json", "X-Actor": "alice"})
+	doRequest(t, router, "DELETE", "/api/books/1", "")
+
+	_, resp := doRequest(t, router, "GET", "/api/audit", "")
+	if got := auditActions(auditEntries(t, resp)); got != "create,delete" {
+		t.Errorf("Expected create,delete, got %s", got)
+	}
+
+	_, resp = doRequest(t, router, "GET", "/api/audit?actor=alice", "")
+	entries := auditEntries(t, resp)
+	if len(entries) != 1 || entries[0].BookID != 4 || len(entries[0].Changes) != 4 {
+		t.Errorf("Unexpected entries for alice %+v", entries)
+	}
+
+	_, resp = doRequest(t, router, "GET", "/api/audit?action=delete&bookId=1", "")
+	if entries := auditEntries(t, resp); len(entries) != 1 || entries[0].Actor != models.AnonymousActor {
+		t.Errorf("Expected one anonymous delete, got %+v", entries)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	_, resp = doRequest(t, router, "GET", "/api/audit?since="+future, "")
+	if entries := auditEntries(t, resp); len(entries) != 0 {
+		t.Errorf("Expected no entries after %s, got %d", future, len(entries))
+	}
+
+	if rec, _ := doRequest(t, router, "GET", "/api/audit?action=rename", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown action, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/9999/history", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown book, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/3/history", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for an unchanged book, got %d", rec.Code)
+	}
+}
+
+func TestStoresAuditEveryWrite(t *testing.T) {
+	t.Parallel()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		ctx := database.WithActor(context.Background(), "carol")
+		book, _ := store.Create(ctx, models.CreateBookRequest{Title: "Audited", Author: "A", Year: 2000, Status: "read"})
+		store.Update(ctx, book.ID, models.UpdateBookRequest{Title: "Audited 2", Author: "A", Year: 2000, Status: "read"}, database.AnyVersion)
+		store.Delete(ctx, book.ID, database.AnyVersion)
+		store.Restore(ctx, book.ID)
+		store.Delete(ctx, book.ID, database.AnyVersion)
+		store.Purge(database.WithActor(context.Background(), models.SystemActor), time.Now().Add(time.Hour))
+
+		// A rolled back batch leaves no trace
+		store.ApplyBatch(ctx, []models.BatchOperation{
+			{Op: models.BatchCreate, Book: models.CreateBookRequest{Title: "Ghost", Author: "A", Year: 2000, Status: "read"}},
+			{Op: models.BatchDelete, ID: 9999},
+		}, true)
+
+		entries, err := store.AuditLog(context.Background(), models.AuditFilter{BookID: book.ID})
+		if err != nil {
+			t.Fatalf("%s: audit log failed: %v", name, err)
+		}
+		var actions []string
+		for _, entry := range entries {
+			actions = append(actions, entry.Action)
+		}
+		if got := strings.Join(actions, ","); got != "create,update,delete,restore,delete,purge" {
+			t.Errorf("%s: unexpected actions %s", name, got)
+		}
+		if entries[1].Actor != "carol" || len(entries[1].Changes) != 1 || entries[1].Changes[0].Field != "title" {
+			t.Errorf("%s: unexpected update entry %+v", name, entries[1])
+		}
+		if entries[5].Actor != models.SystemActor {
+			t.Errorf("%s: expected purge by %s, got %s", name, models.SystemActor, entries[5].Actor)
+		}
+
+		ghost, _ := store.AuditLog(context.Background(), models.AuditFilter{BookID: book.ID + 1})
+		if len(ghost) != 0 {
+			t.Errorf("%s: expected rolled back batch to leave no entries, got %+v", name, ghost)
+		}
+	}
+}
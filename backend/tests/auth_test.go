@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+// signJWT builds a compact JWS over claims; sign receives the signing input
+func signJWT(t *testing.T, alg string, claims map[string]interface{}, sign func([]byte) []byte) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to encode JWT segment: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := encode(map[string]string{"alg": alg, "typ": "JWT"}) + "." + encode(claims)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+// tokenFor returns a valid HS256 token for subject
+func tokenFor(t *testing.T, subject string) string {
+	t.Helper()
+	return signJWT(t, "HS256", map[string]interface{}{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, hs256(testJWTSecret))
+}
+
+func newAuthTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	authenticator := auth.NewAuthenticator(auth.Config{
+		APIKeys:    map[string]models.Principal{"ci-key": {Subject: "ci-bot", Roles: []string{auth.RoleAdmin}}},
+		HMACSecret: testJWTSecret,
+		Audience:   "elibrary",
+	})
+	return newTestRouterWith(t, newTestStore(t), authenticator)
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Content-Type": "application/json", "Authorization": "Bearer " + token}
+}
+
+func TestAuthRejectsMissingAndInvalidCredentials(t *testing.T) {
+	t.Parallel()
+	router := newAuthTestRouter(t)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	cases := map[string]map[string]string{
+		"no credentials":   {},
+		"wrong API key":    {auth.APIKeyHeader: "nope"},
+		"malformed token":  {"Authorization": "Bearer abc.def"},
+		"wrong secret":     {"Authorization": "Bearer " + signJWT(t, "HS256", map[string]interface{}{"sub": "alice", "aud": "elibrary", "exp": exp}, hs256([]byte("other")))},
+		"unsigned token":   {"Authorization": "Bearer " + signJWT(t, "none", map[string]interface{}{"sub": "alice", "aud": "elibrary", "exp": exp}, func([]byte) []byte { return nil })},
+		"unconfigured alg": {"Authorization": "Bearer " + signJWT(t, "RS256", map[string]interface{}{"sub": "alice", "aud": "elibrary", "exp": exp}, hs256(testJWTSecret))},
+		"wrong audience":   {"Authorization": "Bearer " + signJWT(t, "HS256", map[string]interface{}{"sub": "alice", "aud": "other", "exp": exp}, hs256(testJWTSecret))},
+		"no expiry":        {"Authorization": "Bearer " + signJWT(t, "HS256", map[string]interface{}{"sub": "alice", "aud": "elibrary"}, hs256(testJWTSecret))},
+		"expired":          {"Authorization": "Bearer " + signJWT(t, "HS256", map[string]interface{}{"sub": "alice", "aud": "elibrary", "exp": time.Now().Add(-time.Hour).Unix()}, hs256(testJWTSecret))},
+	}
+	for name, headers := range cases {
+		rec, resp := doRequestWithHeaders(t, router, "DELETE", "/api/books/1", "", headers)
+		if rec.Code != http.StatusUnauthorized || resp.Code != constants.CodeUnauthorized || resp.Success {
+			t.Errorf("%s: expected 401 %q, got %d %q", name, constants.CodeUnauthorized, rec.Code, resp.Code)
+		}
+		if !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+			t.Errorf("%s: expected a Bearer challenge, got %q", name, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+
+	// The health check stays public
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/health", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected public health check, got %d", rec.Code)
+	}
+	t.Logf("\n🔒 %d kinds of bad credentials rejected", len(cases))
+}
+
+func TestAuthWithoutCredentialsFailsClosed(t *testing.T) {
+	t.Parallel()
+	router := newTestRouterWith(t, newTestStore(t), auth.NewAuthenticator(auth.Config{}))
+
+	for _, headers := range []map[string]string{{}, {auth.APIKeyHeader: "anything"}, bearer(tokenFor(t, "alice"))} {
+		if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/books", "", headers); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 from an authenticator without credentials, got %d for %v", rec.Code, headers)
+		}
+	}
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/health", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected public health check, got %d", rec.Code)
+	}
+}
+
+func TestAuthAcceptsAPIKeysAndTokens(t *testing.T) {
+	t.Parallel()
+	router := newAuthTestRouter(t)
+
+	token := signJWT(t, "HS256", map[string]interface{}{
+		"sub":   "alice",
+		"aud":   []string{"elibrary", "other"},
+		"roles": []string{auth.RoleLibrarian},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}, hs256(testJWTSecret))
+	body := `{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read"}`
+	if rec, resp := doRequestWithHeaders(t, router, "POST", "/api/books", body, bearer(token)); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 with a bearer token, got %d %q", rec.Code, resp.Error)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json", auth.APIKeyHeader: "ci-key"}
+	if rec, resp := doRequestWithHeaders(t, router, "DELETE", "/api/books/4", "", headers); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 with an API key, got %d %q", rec.Code, resp.Error)
+	}
+
+	// Changes are attributed to the principal
+	rec, resp := doRequestWithHeaders(t, router, "GET", "/api/books/4/history", "", map[string]string{"Authorization": "ApiKey ci-key"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %q", rec.Code, resp.Error)
+	}
+	entries := auditEntries(t, resp)
+	if len(entries) != 2 || entries[0].Actor != "alice" || entries[1].Actor != "ci-bot" {
+		t.Errorf("Expected changes by alice then ci-bot, got %+v", entries)
+	}
+
+	// A principal cannot act under another name
+	headers["X-Actor"] = "alice"
+	if rec, resp := doRequestWithHeaders(t, router, "DELETE", "/api/books/1", "", headers); rec.Code != http.StatusForbidden || resp.Code != constants.CodeForbidden {
+		t.Errorf("Expected 403 %q for a mismatched X-Actor, got %d %q", constants.CodeForbidden, rec.Code, resp.Code)
+	}
+	t.Logf("\n🔑 API key and HS256 token accepted")
+}
+
+func TestAuthVerifiesRS256Tokens(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	publicKey, err := auth.LoadRSAPublicKey(path)
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+
+	router := newTestRouterWith(t, newTestStore(t), auth.NewAuthenticator(auth.Config{RSAPublicKey: publicKey, Issuer: "https://id.example.com"}))
+	rs256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign: %v", err)
+		}
+		return signature
+	}
+	claims := map[string]interface{}{"sub": "carol", "iss": "https://id.example.com", "exp": time.Now().Add(time.Hour).Unix()}
+
+	if rec, resp := doRequestWithHeaders(t, router, "GET", "/api/books/1", "", bearer(signJWT(t, "RS256", claims, rs256))); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with an RS256 token, got %d %q", rec.Code, resp.Error)
+	}
+
+	// HS256 must not be accepted just because an RSA key is configured
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/books/1", "", bearer(signJWT(t, "HS256", claims, hs256(der)))); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an HS256 token signed with the public key, got %d", rec.Code)
+	}
+
+	claims["iss"] = "https://evil.example.com"
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/books/1", "", bearer(signJWT(t, "RS256", claims, rs256))); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a foreign issuer, got %d", rec.Code)
+	}
+	t.Logf("\n🔏 RS256 token verified with a PEM public key")
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	t.Parallel()
+	keys, err := auth.ParseAPIKeys(" alice:k1, ci-bot:k2 ,")
+	if err != nil || len(keys) != 2 || keys["k1"].Subject != "alice" || keys["k2"].Subject != "ci-bot" {
+		t.Errorf("Unexpected keys %v (%v)", keys, err)
+	}
+	for _, spec := range []string{"alice", "alice:", ":k1", "alice:k1,bob:k1"} {
+		if _, err := auth.ParseAPIKeys(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// batchStatuses extracts the per-item HTTP statuses of a batch response
+func batchStatuses(t *testing.T, resp models.APIResponse) []int {
+	t.Helper()
+	raw, _ := json.Marshal(resp.Data)
+	var items []models.BatchItemResult
+	if err := json.Unmarshal(raw, &items); err != nil {
+		t.Fatalf("Failed to decode batch results: %v", err)
+	}
+	statuses := make([]int, len(items))
+	for i, item := range items {
+		statuses[i] = item.Status
+	}
+	return statuses
+}
+
+const mixedBatch = `[
+	{"op":"create","book":{"title":"Batch One","author":"Bulk","year":2010,"status":"to-read"}},
+	{"op":"update","id":1,"book":{"title":"To Kill a Mockingbird","author":"Harper Lee","year":1960,"status":"reading"}},
+	{"op":"delete","id":9999},
+	{"op":"create","book":{"title":"","author":"Bulk","year":2010,"status":"to-read"}},
+	{"op":"delete","id":2}
+]`
+
+func TestBatchNonAtomicReportsEachItem(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/batch", mixedBatch)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[201 200 404 400 200]" {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+
+	_, list := doRequest(t, router, "GET", "/api/books", "")
+	if ids := bookIDs(t, list); fmt.Sprint(ids) != "[1 3 4]" {
+		t.Errorf("Expected partial application, got %v", ids)
+	}
+	t.Logf("\n📦 Non-atomic batch statuses: %v", batchStatuses(t, resp))
+}
+
+func TestBatchAtomicRollsBack(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	body := `[
+		{"op":"create","book":{"title":"Batch One","author":"Bulk","year":2010,"status":"to-read"}},
+		{"op":"delete","id":2},
+		{"op":"update","id":3,"version":7,"book":{"title":"Gatsby","author":"Fitzgerald","year":1925,"status":"read"}}
+	]`
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/batch?atomic=true", body)
+	if rec.Code != http.StatusUnprocessableEntity || resp.Success {
+		t.Fatalf("Expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[424 424 412]" {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+
+	_, list := doRequest(t, router, "GET", "/api/books", "")
+	if ids := bookIDs(t, list); fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Expected nothing applied, got %v", ids)
+	}
+	if _, found := doRequest(t, router, "GET", "/api/books?q=batch", ""); len(bookIDs(t, found)) != 0 {
+		t.Errorf("Expected rolled back book to be unindexed")
+	}
+
+	// IDs are not burnt by a rolled back batch
+	_, created := doRequest(t, router, "POST", "/api/books", `{"title":"Next","author":"A","year":2000,"status":"read"}`)
+	if id := created.Data.(map[string]interface{})["id"].(float64); id != 4 {
+		t.Errorf("Expected next ID 4, got %v", id)
+	}
+}
+
+func TestBatchRejectsDuplicates(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	body := `[
+		{"op":"create","book":{"title":"Batch One","author":"Bulk","year":2010,"status":"to-read"}},
+		{"op":"create","book":{"title":"to kill a mockingbird","author":"HARPER LEE","year":1960,"status":"read"}}
+	]`
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/batch", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[201 409]" {
+		t.Errorf("Expected the duplicate to be rejected, got %v", statuses)
+	}
+
+	// Atomic mode rolls the whole batch back over the duplicate
+	router = newTestRouter(t)
+	rec, resp = doRequest(t, router, "POST", "/api/books/batch?atomic=true", body)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[424 409]" {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+	_, list := doRequest(t, router, "GET", "/api/books", "")
+	if ids := bookIDs(t, list); fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Expected nothing applied, got %v", ids)
+	}
+
+	// force=true opts out, as it does for POST /api/books
+	rec, resp = doRequest(t, router, "POST", "/api/books/batch?atomic=true&force=true", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[201 201]" {
+		t.Errorf("Expected forced creates to succeed, got %v", statuses)
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books/batch?force=maybe", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid force flag, got %d", rec.Code)
+	}
+}
+
+func TestBatchAtomicRejectsInvalidUpFront(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/batch?atomic=true", mixedBatch)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d", rec.Code)
+	}
+	if statuses := batchStatuses(t, resp); fmt.Sprint(statuses) != "[424 424 424 400 424]" {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	ops := make([]string, 101)
+	for i := range ops {
+		ops[i] = `{"op":"delete","id":1}`
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books/batch", "["+strings.Join(ops, ",")+"]"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for 101 operations, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books/batch", "[]"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty batch, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books/batch?atomic=maybe", `[{"op":"delete","id":1}]`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for bad atomic flag, got %d", rec.Code)
+	}
+}
+
+func TestSQLStoreAtomicBatch(t *testing.T) {
+	t.Parallel()
+	store := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer store.Close()
+	ctx := context.Background()
+
+	ops := []models.BatchOperation{
+		{Op: models.BatchCreate, Book: models.CreateBookRequest{Title: "Tx", Author: "SQL", Year: 2020, Status: "read"}},
+		{Op: models.BatchDelete, ID: 1},
+		{Op: models.BatchDelete, ID: 9999},
+	}
+	results, err := store.ApplyBatch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[2].Err == nil || results[0].Err == nil {
+		t.Errorf("Expected every result to report failure, got %+v", results)
+	}
+	if exists, _ := store.Exists(ctx, 1); !exists {
+		t.Errorf("Expected delete to be rolled back")
+	}
+	if found, _ := store.Search(ctx, "tx"); len(found) != 0 {
+		t.Errorf("Expected rolled back create to be unindexed")
+	}
+
+	results, _ = store.ApplyBatch(ctx, ops[:2], true)
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("Expected batch to commit, got %+v", results)
+	}
+	if found, _ := store.Search(ctx, "tx"); len(found) != 1 {
+		t.Errorf("Expected committed create to be indexed")
+	}
+	if exists, _ := store.Exists(ctx, 1); exists {
+		t.Errorf("Expected delete to be committed")
+	}
+
+	// A duplicate create fails the transaction when duplicates are rejected
+	dupes := []models.BatchOperation{
+		{Op: models.BatchCreate, Book: models.CreateBookRequest{Title: "Fresh", Author: "SQL", Year: 2021, Status: "read"}},
+		{Op: models.BatchCreate, Book: models.CreateBookRequest{Title: "TX", Author: "sql", Year: 2020, Status: "read"}},
+	}
+	results, _ = store.ApplyBatch(database.RejectDuplicates(ctx), dupes, true)
+	if !errors.Is(results[1].Err, database.ErrDuplicateBook) || !errors.Is(results[0].Err, database.ErrBatchRolledBack) {
+		t.Errorf("Expected the duplicate to roll the batch back, got %+v", results)
+	}
+	if found, _ := store.Search(ctx, "fresh"); len(found) != 0 {
+		t.Errorf("Expected the rolled back create to be absent")
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"book-library-backend/auth"
+	"book-library-backend/database"
+	"book-library-backend/handlers"
+	"book-library-backend/middleware"
+	"book-library-backend/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestRouter wires a BookHandler around its own store, mirroring main.go.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	return newTestRouterFor(t, newTestStore(t))
+}
+
+// newTestRouterFor wires a BookHandler around the given store.
+func newTestRouterFor(t *testing.T, store database.BookStore) http.Handler {
+	t.Helper()
+	return newTestRouterWith(t, store, nil)
+}
+
+// newTestRouterWith mirrors the routes and middleware of main.go, requiring
+// the credentials accepted by authenticator when it is not nil.
+func newTestRouterWith(t *testing.T, store database.BookStore, authenticator *auth.Authenticator) http.Handler {
+	t.Helper()
+	h := handlers.NewBookHandler(store)
+
+	router := mux.NewRouter()
+	router.Use(middleware.Authenticate(authenticator, "/api/health"))
+	router.Use(middleware.Authorize(authenticator, auth.DefaultPolicy, auth.RouteActions))
+	router.Use(middleware.Actor)
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
+	api.HandleFunc("/books", h.GetAllBooks).Methods("GET")
+	api.HandleFunc("/books", h.CreateBook).Methods("POST")
+	api.HandleFunc("/books/batch", h.BatchBooks).Methods("POST")
+	api.HandleFunc("/books/import", h.ImportBooks).Methods("POST")
+	api.HandleFunc("/books/export", h.ExportBooks).Methods("GET")
+	api.HandleFunc("/books/trash", h.GetTrash).Methods("GET")
+	api.HandleFunc("/books/duplicates", h.FindDuplicates).Methods("GET")
+	api.HandleFunc("/books/isbn/{isbn}", h.GetBookByISBN).Methods("GET")
+	api.HandleFunc("/books/{id}", h.GetBookByID).Methods("GET")
+	api.HandleFunc("/books/{id}", h.UpdateBook).Methods("PUT")
+	api.HandleFunc("/books/{id}", h.PatchBook).Methods("PATCH")
+	api.HandleFunc("/books/{id}", h.DeleteBook).Methods("DELETE")
+	api.HandleFunc("/books/{id}/restore", h.RestoreBook).Methods("POST")
+	api.HandleFunc("/books/{id}/history", h.GetBookHistory).Methods("GET")
+	api.HandleFunc("/audit", h.GetAuditLog).Methods("GET")
+	api.HandleFunc("/me", h.GetCurrentUser).Methods("GET")
+	api.HandleFunc("/me/books", h.GetShelf).Methods("GET")
+	api.HandleFunc("/me/books/{id}", h.GetShelfBook).Methods("GET")
+	api.HandleFunc("/me/books/{id}", h.PutShelfBook).Methods("PUT")
+	api.HandleFunc("/me/books/{id}", h.DeleteShelfBook).Methods("DELETE")
+	api.HandleFunc("/process-url", handlers.ProcessURL).Methods("POST")
+	return router
+}
+
+// doRequest performs a JSON request against the router and decodes the envelope.
+func doRequest(t *testing.T, router http.Handler, method, target, body string) (*httptest.ResponseRecorder, models.APIResponse) {
+	t.Helper()
+	return doRequestWithHeaders(t, router, method, target, body, map[string]string{"Content-Type": "application/json"})
+}
+
+// doRequestWithHeaders is doRequest with full control over request headers.
+func doRequestWithHeaders(t *testing.T, router http.Handler, method, target, body string, headers map[string]string) (*httptest.ResponseRecorder, models.APIResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp models.APIResponse
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec, resp
+}
+
+func TestHandlerCreateAndGetBook(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books",
+		`{"title":"Dune","author":"Frank Herbert","year":1965,"status":"to-read"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	id := int(resp.Data.(map[string]interface{})["id"].(float64))
+
+	rec, resp = doRequest(t, router, "GET", "/api/books/"+strconv.Itoa(id), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if title := resp.Data.(map[string]interface{})["title"]; title != "Dune" {
+		t.Errorf("Expected title Dune, got %v", title)
+	}
+	t.Logf("\n✅ Handler created and fetched book %d", id)
+}
+
+func TestHandlerIsolatedStores(t *testing.T) {
+	t.Parallel()
+	first := newTestRouter(t)
+	second := newTestRouter(t)
+
+	doRequest(t, first, "DELETE", "/api/books/1", "")
+
+	if rec, _ := doRequest(t, first, "GET", "/api/books/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 from first router, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, second, "GET", "/api/books/1", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 from second router, got %d", rec.Code)
+	}
+	t.Logf("\n🧪 Stores are isolated between handlers")
+}
+
+func TestHandlerUpdateMissingBook(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, _ := doRequest(t, router, "PUT", "/api/books/9999",
+		`{"title":"X","author":"Y","year":2000,"status":"read"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
@@ -3,16 +3,26 @@ package tests
 import (
 	"book-library-backend/database"
 	"book-library-backend/models"
+	"context"
 	"strings"
 	"testing"
 )
 
-func TestMain(m *testing.M) {
-	database.InitMemoryDB()
-	m.Run()
+// newTestStore returns a fresh store so every test runs in isolation.
+func newTestStore(t *testing.T) database.BookStore {
+	t.Helper()
+	store, err := database.InitMemoryDB()
+	if err != nil {
+		t.Fatalf("Failed to initialize store: %v", err)
+	}
+	return store
 }
 
 func TestCreateBook(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
 	bookReq := models.CreateBookRequest{
 		Title:       "Test Book",
 		Author:      "Test Author",
@@ -20,7 +30,7 @@ func TestCreateBook(t *testing.T) {
 		Description: "A test book.",
 		Status:      "to-read",
 	}
-	book, err := database.CreateBook(bookReq)
+	book, err := store.Create(ctx, bookReq)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -31,10 +41,14 @@ func TestCreateBook(t *testing.T) {
 }
 
 func TestGetAllBooks(t *testing.T) {
-	_, _ = database.CreateBook(models.CreateBookRequest{
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	_, _ = store.Create(ctx, models.CreateBookRequest{
 		Title: "Book1", Author: "Author1", Year: 2021, Status: "to-read",
 	})
-	books, err := database.GetAllBooks()
+	books, err := store.List(ctx)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -50,10 +64,14 @@ func TestGetAllBooks(t *testing.T) {
 }
 
 func TestGetBookByID(t *testing.T) {
-	book, _ := database.CreateBook(models.CreateBookRequest{
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	book, _ := store.Create(ctx, models.CreateBookRequest{
 		Title: "Book2", Author: "Author2", Year: 2020, Status: "reading",
 	})
-	found, err := database.GetBookByID(book.ID)
+	found, err := store.Get(ctx, book.ID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -64,7 +82,11 @@ func TestGetBookByID(t *testing.T) {
 }
 
 func TestUpdateBook(t *testing.T) {
-	book, _ := database.CreateBook(models.CreateBookRequest{
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	book, _ := store.Create(ctx, models.CreateBookRequest{
 		Title: "Book3", Author: "Author3", Year: 2019, Status: "read",
 	})
 	updateReq := models.UpdateBookRequest{
@@ -74,7 +96,7 @@ func TestUpdateBook(t *testing.T) {
 		Description: "Updated description.",
 		Status:      "to-read",
 	}
-	updated, err := database.UpdateBook(book.ID, updateReq)
+	updated, err := store.Update(ctx, book.ID, updateReq, database.AnyVersion)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -85,14 +107,18 @@ func TestUpdateBook(t *testing.T) {
 }
 
 func TestDeleteBook(t *testing.T) {
-	book, _ := database.CreateBook(models.CreateBookRequest{
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	book, _ := store.Create(ctx, models.CreateBookRequest{
 		Title: "Book4", Author: "Author4", Year: 2017, Status: "read",
 	})
-	err := database.DeleteBook(book.ID)
+	err := store.Delete(ctx, book.ID, database.AnyVersion)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	_, err = database.GetBookByID(book.ID)
+	_, err = store.Get(ctx, book.ID)
 	if err == nil {
 		t.Errorf("Expected error for deleted book, got nil")
 	}
@@ -100,17 +126,21 @@ func TestDeleteBook(t *testing.T) {
 }
 
 func TestBookExists(t *testing.T) {
-	book, _ := database.CreateBook(models.CreateBookRequest{
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	book, _ := store.Create(ctx, models.CreateBookRequest{
 		Title: "Book5", Author: "Author5", Year: 2016, Status: "to-read",
 	})
-	exists, err := database.BookExists(book.ID)
+	exists, err := store.Exists(ctx, book.ID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 	if !exists {
 		t.Errorf("Expected book to exist")
 	}
-	exists, _ = database.BookExists(9999)
+	exists, _ = store.Exists(ctx, 9999)
 	if exists {
 		t.Errorf("Expected book to not exist")
 	}
@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"book-library-backend/config"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a file named name in a temporary directory
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// envOf returns a lookupEnv reading from env
+func envOf(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	t.Parallel()
+	cfg, opts, err := config.Load(nil, envOf(nil))
+	if err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	if cfg.Server.Addr != ":8080" || cfg.Server.ReadTimeout != 15*time.Second || cfg.Server.IdleTimeout != time.Minute {
+		t.Errorf("Unexpected server defaults %+v", cfg.Server)
+	}
+	if cfg.Database.Driver != "memory" || cfg.Log.Level != "info" || opts.File != "" || opts.PrintConfig {
+		t.Errorf("Unexpected defaults %+v %+v", cfg, opts)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	t.Parallel()
+	file := writeConfigFile(t, "library.yaml", `
+server:
+  addr: ":7000"
+  read_timeout: 5s
+  write_timeout: 5s
+log:
+  level: debug
+database:
+  driver: sqlite
+  path: from-file.db
+`)
+	env := map[string]string{
+		config.FileEnv:        file,
+		"SERVER_READ_TIMEOUT": "20s",
+		"SERVER_ADDR":         ":7001",
+		"LOG_LEVEL":           "",
+	}
+	cfg, opts, err := config.Load([]string{"--addr", ":7002"}, envOf(env))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if opts.File != file {
+		t.Errorf("Expected the file from %s, got %q", config.FileEnv, opts.File)
+	}
+	if cfg.Server.Addr != ":7002" {
+		t.Errorf("Expected the flag to win over the environment, got %q", cfg.Server.Addr)
+	}
+	if cfg.Server.ReadTimeout != 20*time.Second {
+		t.Errorf("Expected the environment to win over the file, got %s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 5*time.Second || cfg.Database.Path != "from-file.db" {
+		t.Errorf("Expected the file to win over the defaults, got %+v %+v", cfg.Server, cfg.Database)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("Expected an empty environment variable to be ignored, got %q", cfg.Log.Level)
+	}
+	if cfg.Server.IdleTimeout != time.Minute {
+		t.Errorf("Expected unset settings to keep their default, got %s", cfg.Server.IdleTimeout)
+	}
+	t.Logf("\n⚙️  addr %s from the flag, read timeout %s from the env", cfg.Server.Addr, cfg.Server.ReadTimeout)
+}
+
+func TestConfigLoadsTOMLAndLists(t *testing.T) {
+	t.Parallel()
+	file := writeConfigFile(t, "library.toml", `
+[rate_limit]
+default = "10/s"
+routes = ["POST /api/books=1/m"]
+
+[cors]
+allowed_origins = ["https://app.example.com"]
+allow_credentials = true
+max_age = "1h"
+`)
+	env := map[string]string{"CORS_ALLOWED_ORIGINS": "https://a.example.com, https://b.example.com"}
+	cfg, _, err := config.Load([]string{"--config", file, "--cors-allow-credentials=false"}, envOf(env))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.RateLimit.Default != "10/s" || len(cfg.RateLimit.Routes) != 1 || cfg.CORS.MaxAge != time.Hour {
+		t.Errorf("Expected the TOML settings, got %+v %+v", cfg.RateLimit, cfg.CORS)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, " "); got != "https://a.example.com https://b.example.com" {
+		t.Errorf("Expected the comma-separated origins from the environment, got %q", got)
+	}
+	if cfg.CORS.AllowCredentials {
+		t.Errorf("Expected the flag to turn credentials off")
+	}
+}
+
+func TestConfigRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"unknown.yaml": "server:\n  port: 8080\n",
+		"unknown.toml": "[server]\nport = 8080\n",
+		"bad.yaml":     "server:\n  read_timeout: soon\n",
+		"library.json": "{}",
+	}
+	for name, content := range cases {
+		file := writeConfigFile(t, name, content)
+		if _, _, err := config.Load([]string{"--config", file}, envOf(nil)); err == nil {
+			t.Errorf("%s: expected the file to be rejected", name)
+		}
+	}
+	if _, _, err := config.Load([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}, envOf(nil)); err == nil {
+		t.Errorf("Expected a missing file to be rejected")
+	}
+}
+
+func TestConfigValidationReportsEveryError(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"LOG_LEVEL":          "loud",
+		"DB_DRIVER":          "postgres",
+		"RATE_LIMIT_DEFAULT": "lots",
+	}
+	_, _, err := config.Load([]string{"--addr", "8080", "--read-timeout", "0s"}, envOf(env))
+	if err == nil {
+		t.Fatalf("Expected the configuration to be rejected")
+	}
+	for _, want := range []string{"server.addr", "server.read_timeout", "log.level", "database.driver", "rate_limit"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %s, got %v", want, err)
+		}
+	}
+
+	if _, _, err := config.Load(nil, envOf(map[string]string{"SERVER_IDLE_TIMEOUT": "forever"})); err == nil || !strings.Contains(err.Error(), "SERVER_IDLE_TIMEOUT") {
+		t.Errorf("Expected a malformed duration to name its variable, got %v", err)
+	}
+	if _, _, err := config.Load([]string{"--no-such-flag"}, envOf(nil)); err == nil {
+		t.Errorf("Expected an unknown flag to be rejected")
+	}
+}
+
+func TestConfigPrintRedactsSecrets(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"AUTH_API_KEYS":   "ci-bot:ci-secret-key:admin",
+		"AUTH_JWT_SECRET": "jwt-signing-secret",
+	}
+	cfg, opts, err := config.Load([]string{"--print-config"}, envOf(env))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if !opts.PrintConfig {
+		t.Errorf("Expected --print-config to be reported")
+	}
+
+	var out bytes.Buffer
+	if err := cfg.WriteYAML(&out); err != nil {
+		t.Fatalf("Failed to print configuration: %v", err)
+	}
+	printed := out.String()
+	for _, secret := range []string{"ci-secret-key", "ci-bot", "jwt-signing-secret"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("Expected %q to be redacted:\n%s", secret, printed)
+		}
+	}
+	if !strings.Contains(printed, "REDACTED") || !strings.Contains(printed, "addr: :8080") {
+		t.Errorf("Unexpected printed configuration:\n%s", printed)
+	}
+	if cfg.Auth.JWTSecret != "jwt-signing-secret" {
+		t.Errorf("Expected printing to leave the configuration intact")
+	}
+
+	t.Logf("\n🔒 Printed configuration:\n%s", printed)
+}
+
+func TestConfigPrintRoundTrips(t *testing.T) {
+	t.Parallel()
+	cfg, _, err := config.Load([]string{"--db-driver", "sqlite", "--trash-retention", "0s"}, envOf(nil))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	var out bytes.Buffer
+	if err := cfg.WriteYAML(&out); err != nil {
+		t.Fatalf("Failed to print configuration: %v", err)
+	}
+
+	file := writeConfigFile(t, "printed.yaml", out.String())
+	reloaded, _, err := config.Load([]string{"--config", file}, envOf(nil))
+	if err != nil {
+		t.Fatalf("Failed to load the printed configuration: %v", err)
+	}
+	var again bytes.Buffer
+	if err := reloaded.WriteYAML(&again); err != nil {
+		t.Fatalf("Failed to print configuration: %v", err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("Expected the printed configuration to load back unchanged:\n%s\n%s", out.String(), again.String())
+	}
+}
+
+func TestConfigAuthFailsClosed(t *testing.T) {
+	t.Parallel()
+	cfg, _, err := config.Load(nil, envOf(nil))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if authenticator, err := cfg.Auth.Authenticator(); !errors.Is(err, config.ErrNoCredentials) || authenticator != nil {
+		t.Errorf("Expected ErrNoCredentials without credentials, got %v %v", authenticator, err)
+	}
+
+	cfg, _, err = config.Load([]string{"--auth-disabled"}, envOf(nil))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if authenticator, err := cfg.Auth.Authenticator(); err != nil || authenticator != nil {
+		t.Errorf("Expected no authenticator when explicitly disabled, got %v %v", authenticator, err)
+	}
+
+	cfg, _, err = config.Load(nil, envOf(map[string]string{"AUTH_JWT_SECRET": "jwt-signing-secret"}))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if authenticator, err := cfg.Auth.Authenticator(); err != nil || !authenticator.Enabled() {
+		t.Errorf("Expected an enabled authenticator, got %v %v", authenticator, err)
+	}
+
+	env := map[string]string{"AUTH_DISABLED": "true", "AUTH_API_KEYS": "ci-bot:ci-secret-key"}
+	if _, _, err := config.Load(nil, envOf(env)); err == nil || !strings.Contains(err.Error(), "auth.disabled") {
+		t.Errorf("Expected disabling auth alongside credentials to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newCORSTestHandler(t *testing.T, config middleware.CORSConfig) http.Handler {
+	t.Helper()
+	cors, err := middleware.NewCORS(config)
+	if err != nil {
+		t.Fatalf("Failed to build CORS policy: %v", err)
+	}
+	return cors.Handler(newTestRouter(t).(*mux.Router))
+}
+
+var testCORSConfig = middleware.CORSConfig{
+	AllowedOrigins:   []string{"https://app.example.com", "https://*.byfood.com", "http://localhost:*"},
+	AllowCredentials: true,
+	AllowedHeaders:   middleware.DefaultCORSAllowedHeaders,
+	ExposedHeaders:   middleware.DefaultCORSExposedHeaders,
+	MaxAge:           time.Hour,
+}
+
+// preflight sends an OPTIONS request announcing method from origin
+func preflight(handler http.Handler, target, origin, method, headers string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("OPTIONS", target, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSPreflightUsesRouteMethods(t *testing.T) {
+	t.Parallel()
+	handler := newCORSTestHandler(t, testCORSConfig)
+
+	rec := preflight(handler, "/api/books/1", "https://app.example.com", "PUT", "content-type, if-match")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, PUT, PATCH, DELETE",
+		"Access-Control-Allow-Headers":     "Content-Type, If-Match",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "3600",
+	}
+	for header, value := range want {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("Expected %s %q, got %q", header, value, got)
+		}
+	}
+
+	if rec := preflight(handler, "/api/books", "https://app.example.com", "POST", ""); rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("Expected GET, POST on /api/books, got %q", rec.Header().Get("Access-Control-Allow-Methods"))
+	}
+	t.Logf("\n🌐 /api/books/{id} allows %s", want["Access-Control-Allow-Methods"])
+}
+
+func TestCORSRejectsDisallowedPreflights(t *testing.T) {
+	t.Parallel()
+	handler := newCORSTestHandler(t, testCORSConfig)
+
+	cases := []struct {
+		name, target, origin, method, headers string
+	}{
+		{"unknown origin", "/api/books", "https://evil.example.com", "GET", ""},
+		{"pattern lookalike", "/api/books", "https://evilbyfood.com", "GET", ""},
+		{"method without route", "/api/books", "https://app.example.com", "DELETE", ""},
+		{"unknown header", "/api/books", "https://app.example.com", "POST", "X-Debug"},
+	}
+	for _, c := range cases {
+		rec := preflight(handler, c.target, c.origin, c.method, c.headers)
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), constants.CodeForbidden) {
+			t.Errorf("%s: expected 403, got %d %s", c.name, rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s: expected no Access-Control-Allow-Origin, got %q", c.name, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+
+	if rec := preflight(handler, "/api/nowhere", "https://app.example.com", "GET", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a preflight to an unknown path, got %d", rec.Code)
+	}
+}
+
+func TestCORSDecoratesAllowedOrigins(t *testing.T) {
+	t.Parallel()
+	handler := newCORSTestHandler(t, testCORSConfig)
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.com":   true,
+		"https://shop.byfood.com":   true,
+		"http://localhost:3000":     true,
+		"https://app.example.com.x": false,
+		"http://byfood.com":         false,
+	} {
+		req := httptest.NewRequest("GET", "/api/books/1", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected the request to be served, got %d", origin, rec.Code)
+		}
+		got := rec.Header().Get("Access-Control-Allow-Origin")
+		if allowed && (got != origin || !strings.Contains(rec.Header().Get("Access-Control-Expose-Headers"), "ETag")) {
+			t.Errorf("%s: expected the origin to be allowed with exposed headers, got %v", origin, rec.Header())
+		}
+		if !allowed && got != "" {
+			t.Errorf("%s: expected no CORS headers, got %q", origin, got)
+		}
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Errorf("%s: expected Vary: Origin, got %q", origin, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestCORSConfigValidation(t *testing.T) {
+	t.Parallel()
+	if _, err := middleware.NewCORS(middleware.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}); err == nil {
+		t.Errorf("Expected credentials with any origin to be rejected")
+	}
+	if _, err := middleware.NewCORS(middleware.CORSConfig{AllowedOrigins: []string{"https://[.example.com*"}}); err == nil {
+		t.Errorf("Expected a malformed pattern to be rejected")
+	}
+
+	cors, err := middleware.NewCORS(middleware.CORSConfig{AllowedOrigins: []string{"*"}})
+	if err != nil || !cors.AllowsOrigin("https://anything.example") {
+		t.Errorf("Expected * to allow any origin (%v)", err)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStrictJSONRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books",
+		`{"title":"Dune","auther":"Frank Herbert","year":1965,"status":"read"}`)
+	if rec.Code != http.StatusBadRequest || resp.Code != constants.CodeInvalidJSON {
+		t.Fatalf("Expected 400 %q, got %d %q", constants.CodeInvalidJSON, rec.Code, resp.Code)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "auther" || resp.Errors[0].Code != constants.FieldUnknown {
+		t.Errorf("Expected the unknown field to be reported, got %v", resp.Errors)
+	}
+	position := resp.Data.(map[string]interface{})
+	if position["line"] != float64(1) || position["column"] != float64(17) {
+		t.Errorf("Expected the error at line 1, column 17, got %v", position)
+	}
+	t.Logf("\n🚫 %s", resp.Error)
+}
+
+func TestStrictJSONReportsPositions(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	cases := []struct {
+		target, body string
+		line, column float64
+		field        string
+	}{
+		{"/api/books", "{\n  \"title\": \"Dune\",\n  \"year\": \"1965\"\n}", 3, 16, "year"},
+		{"/api/books", "{\"title\": \"Dune\",,}", 1, 18, ""},
+		{"/api/books/1", `{"title":"Dune"} {"title":"Emma"}`, 1, 18, ""},
+		{"/api/books/batch", `[{"op":"create","book":{"title":"Dune","autor":"x"}}]`, 1, 40, "autor"},
+		{"/api/process-url", `{"url":"https://byfood.com","operation":"all"`, 1, 46, ""},
+	}
+	for _, c := range cases {
+		method := "POST"
+		if c.target == "/api/books/1" {
+			method = "PUT"
+		}
+		rec, resp := doRequest(t, router, method, c.target, c.body)
+		if rec.Code != http.StatusBadRequest || resp.Code != constants.CodeInvalidJSON {
+			t.Errorf("%s %q: expected 400 %q, got %d %q", method, c.body, constants.CodeInvalidJSON, rec.Code, resp.Code)
+			continue
+		}
+		position := resp.Data.(map[string]interface{})
+		if position["line"] != c.line || position["column"] != c.column {
+			t.Errorf("%q: expected line %v column %v, got %v (%s)", c.body, c.line, c.column, position, resp.Error)
+		}
+		if c.field != "" && (len(resp.Errors) != 1 || resp.Errors[0].Field != c.field) {
+			t.Errorf("%q: expected field %s to be reported, got %v", c.body, c.field, resp.Errors)
+		}
+	}
+}
+
+func TestStrictJSONChecksContentTypeAndSize(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	body := `{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read"}`
+
+	rec, resp := doRequestWithHeaders(t, router, "POST", "/api/books", body, map[string]string{"Content-Type": "text/plain"})
+	if rec.Code != http.StatusUnsupportedMediaType || resp.Code != constants.CodeUnsupportedMediaType {
+		t.Errorf("Expected 415 for text/plain, got %d %q", rec.Code, resp.Code)
+	}
+	rec, _ = doRequestWithHeaders(t, router, "POST", "/api/process-url", `{"url":"https://byfood.com","operation":"all"}`, nil)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 without Content-Type, got %d", rec.Code)
+	}
+	if rec, _ := doRequestWithHeaders(t, router, "POST", "/api/books", body, map[string]string{"Content-Type": "application/json; charset=utf-8"}); rec.Code != http.StatusCreated {
+		t.Errorf("Expected charset parameter to be accepted, got %d", rec.Code)
+	}
+
+	huge := `{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read","description":"` + strings.Repeat("x", 1<<20) + `"}`
+	rec, resp = doRequest(t, router, "POST", "/api/books", huge)
+	if rec.Code != http.StatusRequestEntityTooLarge || resp.Code != constants.CodePayloadTooLarge {
+		t.Errorf("Expected 413 for a body over 1 MB, got %d %q", rec.Code, resp.Code)
+	}
+	hugePatch := `{"description":"` + strings.Repeat("x", 1<<20) + `"}`
+	rec, resp = doRequestWithHeaders(t, router, "PATCH", "/api/books/1", hugePatch, map[string]string{"Content-Type": "application/merge-patch+json"})
+	if rec.Code != http.StatusRequestEntityTooLarge || resp.Code != constants.CodePayloadTooLarge {
+		t.Errorf("Expected 413 for a PATCH body over 1 MB, got %d %q", rec.Code, resp.Code)
+	}
+
+	if rec, resp := doRequest(t, router, "POST", "/api/books", "  "); rec.Code != http.StatusBadRequest || resp.Error != constants.ErrEmptyBody {
+		t.Errorf("Expected 400 %q for an empty body, got %d %q", constants.ErrEmptyBody, rec.Code, resp.Error)
+	}
+	t.Logf("\n📦 Content-Type and size limits enforced")
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/search"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateRejectsDuplicates(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	body := `{"title":"the great  gatsby!","author":"F. SCOTT FITZGERALD","year":1925,"status":"read"}`
+
+	rec, resp := doRequest(t, router, "POST", "/api/books", body)
+	if rec.Code != http.StatusConflict || resp.Error != constants.ErrBookAlreadyExists {
+		t.Fatalf("Expected 409 %q, got %d %q", constants.ErrBookAlreadyExists, rec.Code, resp.Error)
+	}
+	if data := resp.Data.(map[string]interface{}); data["existing_id"] != float64(3) {
+		t.Errorf("Expected existing_id 3, got %v", data)
+	}
+
+	if rec, _ := doRequest(t, router, "POST", "/api/books?force=maybe", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid force flag, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books?force=true", body); rec.Code != http.StatusCreated {
+		t.Errorf("Expected forced create to succeed, got %d", rec.Code)
+	}
+	t.Logf("\n👯 Duplicate of book 3 rejected unless forced")
+}
+
+func TestConcurrentCreatesRejectDuplicates(t *testing.T) {
+	t.Parallel()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	for name, store := range map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore} {
+		router := newTestRouterFor(t, store)
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		codes := make(map[int]int)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				body := fmt.Sprintf(`{"title":"Dune%s","author":"Frank Herbert","year":1965,"status":"read"}`, []string{"", "!", "  "}[i%3])
+				rec, _ := doRequest(t, router, "POST", "/api/books", body)
+				mutex.Lock()
+				codes[rec.Code]++
+				mutex.Unlock()
+			}(i)
+		}
+		wg.Wait()
+		if codes[http.StatusCreated] != 1 || codes[http.StatusConflict] != 19 {
+			t.Errorf("%s: expected one create and 19 conflicts, got %v", name, codes)
+		}
+
+		// Trashed books do not count as duplicates
+		_, err := store.Create(database.RejectDuplicates(context.Background()),
+			models.CreateBookRequest{Title: "DUNE", Author: "frank herbert", Year: 1965, Status: "read"})
+		var duplicate *database.DuplicateBookError
+		if !errors.As(err, &duplicate) || !errors.Is(err, database.ErrDuplicateBook) {
+			t.Fatalf("%s: expected a DuplicateBookError, got %v", name, err)
+		}
+		if err := store.Delete(context.Background(), duplicate.ExistingID, database.AnyVersion); err != nil {
+			t.Fatalf("%s: failed to delete book: %v", name, err)
+		}
+		if _, err := store.Create(database.RejectDuplicates(context.Background()),
+			models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: 1965, Status: "read"}); err != nil {
+			t.Errorf("%s: expected the trashed copy to be ignored, got %v", name, err)
+		}
+	}
+	t.Logf("\n👯 20 concurrent creates of Dune: one accepted")
+}
+
+func TestDuplicatesReportGroupsSimilarBooks(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	doRequest(t, router, "POST", "/api/books", `{"title":"The Great Gatsbby","author":"Scott Fitzgerald","year":1925,"status":"read"}`)
+	doRequest(t, router, "POST", "/api/books", `{"title":"Nineteen Eighty-Four","author":"George Orwell","year":1949,"status":"read"}`)
+
+	rec, resp := doRequest(t, router, "GET", "/api/books/duplicates", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	raw, _ := json.Marshal(resp.Data)
+	var groups []models.DuplicateGroup
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		t.Fatalf("Failed to decode groups: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Books) != 2 || groups[0].Books[0].ID != 3 || groups[0].Books[1].ID != 4 {
+		t.Fatalf("Expected books 3 and 4 grouped, got %+v", groups)
+	}
+	if groups[0].Score < 0.85 || groups[0].Score >= 1 {
+		t.Errorf("Unexpected group score %v", groups[0].Score)
+	}
+
+	if rec, _ := doRequest(t, router, "GET", "/api/books/duplicates?threshold=1.5", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid threshold, got %d", rec.Code)
+	}
+}
+
+func TestDuplicatesReportScalesWithCatalog(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+	words := []string{"Shadow", "River", "Empire", "Garden", "Winter", "Ocean", "Mirror", "Forest", "Silence", "Harbor"}
+	for i := 0; i < 10000; i++ {
+		req := models.CreateBookRequest{
+			Title:  fmt.Sprintf("%s %s %d", words[i%len(words)], words[(i/len(words))%len(words)], i),
+			Author: fmt.Sprintf("Writer %d", i),
+			Year:   2000,
+			Status: "to-read",
+		}
+		if _, err := store.Create(ctx, req); err != nil {
+			t.Fatalf("Failed to create book %d: %v", i, err)
+		}
+	}
+	planted, err := store.Create(ctx, models.CreateBookRequest{Title: "The Great Gatsbby", Author: "Scott Fitzgerald", Year: 1925, Status: "read"})
+	if err != nil {
+		t.Fatalf("Failed to create book: %v", err)
+	}
+	router := newTestRouterFor(t, store)
+
+	started := time.Now()
+	rec, resp := doRequest(t, router, "GET", "/api/books/duplicates", "")
+	elapsed := time.Since(started)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	raw, _ := json.Marshal(resp.Data)
+	var groups []models.DuplicateGroup
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		t.Fatalf("Failed to decode groups: %v", err)
+	}
+	if resp.Pagination == nil || resp.Pagination.Limit != 100 || len(groups) > 100 {
+		t.Errorf("Expected the report to be paginated by 100, got %d groups and %+v", len(groups), resp.Pagination)
+	}
+	found := false
+	for _, group := range groups {
+		for _, book := range group.Books {
+			found = found || book.ID == planted.ID
+		}
+	}
+	if !found {
+		t.Errorf("Expected book %d to be reported among %+v", planted.ID, groups)
+	}
+	t.Logf("\n👯 Duplicates among 10000 books found in %s", elapsed)
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"The Hobbit", "the  hobbit!", 1, 1},
+		{"Émile Zola", "Emile Zola", 1, 1},
+		{"F. Scott Fitzgerald", "Scott Fitzgerald", 0.85, 0.95},
+		{"Dune", "Emma", 0, 0.3},
+		{"", "", 1, 1},
+	}
+	for _, c := range cases {
+		if got := search.Similarity(c.a, c.b); got < c.min || got > c.max {
+			t.Errorf("Similarity(%q, %q) = %v, want between %v and %v", c.a, c.b, got, c.min, c.max)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorResponsesCarryCodes(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	cases := []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{"GET", "/api/books/abc", "", http.StatusBadRequest, constants.CodeInvalidID},
+		{"GET", "/api/books/999", "", http.StatusNotFound, constants.CodeBookNotFound},
+		{"POST", "/api/books", "{", http.StatusBadRequest, constants.CodeInvalidJSON},
+		{"GET", "/api/books?limit=-1", "", http.StatusBadRequest, constants.CodeInvalidParameter},
+		{"POST", "/api/books/1/restore", "", http.StatusConflict, constants.CodeBookNotInTrash},
+		{"GET", "/api/books/export?format=pdf", "", http.StatusBadRequest, constants.CodeInvalidParameter},
+	}
+	for _, c := range cases {
+		rec, resp := doRequest(t, router, c.method, c.target, c.body)
+		if rec.Code != c.status || resp.Code != c.code || resp.Error == "" {
+			t.Errorf("%s %s: expected %d %q with a message, got %d %q %q", c.method, c.target, c.status, c.code, rec.Code, resp.Code, resp.Error)
+		}
+	}
+
+	rec, resp := doRequestWithHeaders(t, router, "PUT", "/api/books/1",
+		`{"title":"To Kill a Mockingbird","author":"Harper Lee","year":1960,"status":"read"}`,
+		map[string]string{"Content-Type": "application/json", "If-Match": `"99"`})
+	if rec.Code != http.StatusPreconditionFailed || resp.Code != constants.CodeVersionMismatch {
+		t.Errorf("Expected 412 %q, got %d %q", constants.CodeVersionMismatch, rec.Code, resp.Code)
+	}
+	t.Logf("\n🏷️ Error responses carry stable codes")
+}
+
+func TestValidationErrorsListFields(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books",
+		`{"title":" ","author":"Frank Herbert","isbn":"123","year":99,"status":"lost"}`)
+	if rec.Code != http.StatusBadRequest || resp.Code != constants.CodeValidationFailed {
+		t.Fatalf("Expected 400 %q, got %d %q", constants.CodeValidationFailed, rec.Code, resp.Code)
+	}
+
+	got := make(map[string]string)
+	for _, field := range resp.Errors {
+		got[field.Field] = field.Code
+		if field.Message == "" {
+			t.Errorf("Expected a message for field %s", field.Field)
+		}
+	}
+	want := map[string]string{
+		"title":  constants.FieldRequired,
+		"year":   constants.FieldMin,
+		"isbn":   constants.FieldISBN,
+		"status": constants.FieldOneOf,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected fields %v, got %v", want, got)
+	}
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("Expected %s to fail %q, got %q", field, code, got[field])
+		}
+	}
+	t.Logf("\n📋 Validation errors: %v", got)
+}
+
+func TestProblemJSONOnRequest(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, _ := doRequestWithHeaders(t, router, "POST", "/api/books", `{"title":"","author":"","year":1960,"status":"read"}`,
+		map[string]string{"Content-Type": "application/json", "Accept": "application/problem+json"})
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != utils.ProblemContentType {
+		t.Fatalf("Expected 400 problem+json, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var problem models.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem %q: %v", rec.Body.String(), err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Title != "Bad Request" || problem.Instance != "/api/books" ||
+		problem.Code != constants.CodeValidationFailed || len(problem.Errors) != 2 {
+		t.Errorf("Unexpected problem details %+v", problem)
+	}
+
+	// Successful responses keep the envelope
+	rec, resp := doRequestWithHeaders(t, router, "GET", "/api/books/1", "", map[string]string{"Accept": "application/problem+json"})
+	if rec.Code != http.StatusOK || !resp.Success {
+		t.Errorf("Expected the usual envelope on success, got %d %s", rec.Code, rec.Body.String())
+	}
+	t.Logf("\n🧾 Problem details: %s", problem.Detail)
+}
+
+func TestStoresReturnSentinelErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		if _, err := store.Get(ctx, 999); !errors.Is(err, database.ErrBookNotFound) {
+			t.Errorf("%s: expected ErrBookNotFound, got %v", name, err)
+		}
+
+		book, err := store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", ISBN: "0441172717", Year: 1965, Status: "read"})
+		if err != nil {
+			t.Fatalf("%s: failed to create book: %v", name, err)
+		}
+		if err := store.Delete(ctx, book.ID, book.Version+1); !errors.Is(err, database.ErrVersionMismatch) {
+			t.Errorf("%s: expected ErrVersionMismatch, got %v", name, err)
+		}
+		if _, err := store.Restore(ctx, book.ID); !errors.Is(err, database.ErrBookNotInTrash) {
+			t.Errorf("%s: expected ErrBookNotInTrash, got %v", name, err)
+		}
+		if _, err := store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719", Year: 1965, Status: "read"}); !errors.Is(err, database.ErrDuplicateISBN) {
+			t.Errorf("%s: expected ErrDuplicateISBN, got %v", name, err)
+		}
+		if _, err := store.Create(ctx, models.CreateBookRequest{Title: "Emma", Author: "Jane Austen", ISBN: "123", Year: 1815, Status: "read"}); !errors.Is(err, utils.ErrInvalidISBN) {
+			t.Errorf("%s: expected ErrInvalidISBN, got %v", name, err)
+		}
+	}
+}
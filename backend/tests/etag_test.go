@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestETagOnReadAndNotModified(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, _ := doRequest(t, router, "GET", "/api/books/1", "")
+	etag := rec.Header().Get("ETag")
+	if etag != `"1.1"` {
+		t.Fatalf("Expected ETag \"1.1\", got %q", etag)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "GET", "/api/books/1", "", map[string]string{"If-None-Match": "W/" + etag})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d with %q", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "GET", "/api/books/1", "", map[string]string{"If-None-Match": `"1.0"`})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for stale If-None-Match, got %d", rec.Code)
+	}
+	t.Logf("\n🏷️ ETag %s honoured by If-None-Match", etag)
+}
+
+func TestIfMatchPreventsLostUpdates(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	body := `{"title":"1984","author":"George Orwell","year":1949,"status":"read"}`
+
+	rec, _ := doRequestWithHeaders(t, router, "PUT", "/api/books/2", body,
+		map[string]string{"Content-Type": "application/json", "If-Match": `"2.1"`})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected first editor to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"2.2"` {
+		t.Errorf("Expected new ETag \"2.2\", got %q", etag)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "PUT", "/api/books/2", body,
+		map[string]string{"Content-Type": "application/json", "If-Match": `"2.1"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected second editor to get 412, got %d", rec.Code)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "PATCH", "/api/books/2", `{"status":"to-read"}`,
+		map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": `"2.1"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected stale PATCH to get 412, got %d", rec.Code)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "DELETE", "/api/books/2", "", map[string]string{"If-Match": `"2.1"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected stale DELETE to get 412, got %d", rec.Code)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "DELETE", "/api/books/2", "", map[string]string{"If-Match": `"2.2"`})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected current DELETE to succeed, got %d", rec.Code)
+	}
+}
+
+func TestStoreVersionChecks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		book, _ := store.Create(ctx, models.CreateBookRequest{Title: "V", Author: "A", Year: 2000, Status: "read"})
+		if book.Version != 1 {
+			t.Errorf("%s: expected version 1 on create, got %d", name, book.Version)
+		}
+
+		req := models.UpdateBookRequest{Title: "V2", Author: "A", Year: 2000, Status: "read"}
+		updated, err := store.Update(ctx, book.ID, req, 1)
+		if err != nil || updated.Version != 2 {
+			t.Fatalf("%s: expected version 2, got %v (%v)", name, updated, err)
+		}
+		if _, err := store.Update(ctx, book.ID, req, 1); err == nil || err.Error() != constants.ErrVersionMismatch {
+			t.Errorf("%s: expected version mismatch, got %v", name, err)
+		}
+		if err := store.Delete(ctx, book.ID, 1); err == nil {
+			t.Errorf("%s: expected stale delete to fail", name)
+		}
+		if err := store.Delete(ctx, book.ID, 2); err != nil {
+			t.Errorf("%s: expected delete at version 2 to succeed, got %v", name, err)
+		}
+	}
+}
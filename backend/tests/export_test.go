@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// exportRequest performs an export request and returns the raw recorder.
+func exportRequest(t *testing.T, router http.Handler, target, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExportNDJSONByDefault(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec := exportRequest(t, router, "/api/books/export", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/x-ndjson") {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, ".ndjson") {
+		t.Errorf("Expected .ndjson attachment, got %q", cd)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first["id"] != float64(1) {
+		t.Errorf("Unexpected first line %q: %v", lines[0], err)
+	}
+	t.Logf("\n📤 NDJSON export: %d lines", len(lines))
+}
+
+func TestExportCSVAppliesFiltersAndSort(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec := exportRequest(t, router, "/api/books/export?status=read,to-read&sort=-year", "text/csv")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "id" || rows[0][1] != "title" {
+		t.Fatalf("Unexpected CSV %v", rows)
+	}
+	if rows[1][0] != "1" || rows[2][0] != "3" {
+		t.Errorf("Expected books 1 then 3, got %s then %s", rows[1][0], rows[2][0])
+	}
+}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, _ := doRequest(t, router, "POST", "/api/books",
+		`{"title":"=HYPERLINK(\"http://evil.example\")","author":"@Mallory","year":2001,"description":"-1 star","status":"read"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = exportRequest(t, router, "/api/books/export?format=csv", "")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("Unexpected CSV %v: %v", rows, err)
+	}
+	if got := rows[4]; got[1] != `'=HYPERLINK("http://evil.example")` || got[2] != "'@Mallory" || got[5] != "'-1 star" {
+		t.Errorf("Expected formula cells to be quoted, got %v", got)
+	}
+	if strings.HasPrefix(rows[1][1], "'") {
+		t.Errorf("Expected plain cells to stay as is, got %q", rows[1][1])
+	}
+}
+
+func TestExportDublinCoreAndMARCXML(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec := exportRequest(t, router, "/api/books/export?format=dc&title=gatsby", "")
+	var dc struct {
+		Records []struct {
+			Identifier string `xml:"identifier"`
+			Title      string `xml:"title"`
+			Creator    string `xml:"creator"`
+		} `xml:"record"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &dc); err != nil {
+		t.Fatalf("Failed to parse Dublin Core: %v\n%s", err, rec.Body.String())
+	}
+	if len(dc.Records) != 1 || dc.Records[0].Title != "The Great Gatsby" || dc.Records[0].Identifier != "3" {
+		t.Errorf("Unexpected Dublin Core records %+v", dc.Records)
+	}
+
+	rec = exportRequest(t, router, "/api/books/export", "application/marcxml+xml")
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/marcxml+xml") {
+		t.Errorf("Expected MARCXML content type, got %q", ct)
+	}
+	var marc struct {
+		Records []struct {
+			ControlFields []string `xml:"controlfield"`
+			DataFields    []struct {
+				Tag       string   `xml:"tag,attr"`
+				Subfields []string `xml:"subfield"`
+			} `xml:"datafield"`
+		} `xml:"record"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &marc); err != nil {
+		t.Fatalf("Failed to parse MARCXML: %v", err)
+	}
+	if len(marc.Records) != 3 || marc.Records[0].ControlFields[0] != "1" {
+		t.Fatalf("Unexpected MARC records %+v", marc.Records)
+	}
+	for _, field := range marc.Records[1].DataFields {
+		if field.Tag == "245" && field.Subfields[0] != "1984" {
+			t.Errorf("Expected 245$a 1984, got %v", field.Subfields)
+		}
+	}
+}
+
+func TestExportRejectsUnknownFormats(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	if rec := exportRequest(t, router, "/api/books/export?format=pdf", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown format, got %d", rec.Code)
+	}
+	if rec := exportRequest(t, router, "/api/books/export", "application/pdf"); rec.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406 for unacceptable Accept, got %d", rec.Code)
+	}
+	if rec := exportRequest(t, router, "/api/books/export", "application/pdf, */*;q=0.1"); rec.Code != http.StatusOK {
+		t.Errorf("Expected wildcard Accept to fall back to NDJSON, got %d", rec.Code)
+	}
+}
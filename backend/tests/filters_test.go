@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFilterByStatusMultiValued(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	for _, target := range []string{
+		"/api/books?status=read&status=reading",
+		"/api/books?status=read,reading",
+	} {
+		rec, resp := doRequest(t, router, "GET", target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d", target, rec.Code)
+		}
+		if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[1 2 4 6]" {
+			t.Errorf("Unexpected books for %s: %v", target, ids)
+		}
+	}
+}
+
+func TestFilterByYearRange(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	_, resp := doRequest(t, router, "GET", "/api/books?yearFrom=1812&yearTo=1950", "")
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[2 3 4 5]" {
+		t.Errorf("Unexpected books %v", ids)
+	}
+}
+
+func TestFilterByTimestamps(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	_, resp := doRequest(t, router, "GET", "/api/books?updatedSince=2000-01-01", "")
+	if ids := bookIDs(t, resp); len(ids) != 6 {
+		t.Errorf("Expected every book updated since 2000, got %v", ids)
+	}
+	_, resp = doRequest(t, router, "GET", "/api/books?createdAfter=2999-01-01T00:00:00Z", "")
+	if ids := bookIDs(t, resp); len(ids) != 0 {
+		t.Errorf("Expected no book created after 2999, got %v", ids)
+	}
+}
+
+func TestFilterRejectsMalformedValues(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	for _, target := range []string{
+		"/api/books?status=lost",
+		"/api/books?yearFrom=abc",
+		"/api/books?yearFrom=2000&yearTo=1900",
+		"/api/books?createdAfter=yesterday",
+		"/api/books?updatedSince=2024-13-45",
+	} {
+		if rec, _ := doRequest(t, router, "GET", target, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestFacetsIgnoreTheirOwnFilter(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	_, resp := doRequest(t, router, "GET", "/api/books?status=read&yearTo=1900", "")
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[4]" {
+		t.Fatalf("Unexpected books %v", ids)
+	}
+	f := resp.Facets
+	if f == nil {
+		t.Fatalf("Expected facets in response")
+	}
+	// Status counts ignore status=read but honour yearTo=1900 (the Austen books)
+	if f.Status["read"] != 1 || f.Status["reading"] != 1 || f.Status["to-read"] != 1 {
+		t.Errorf("Unexpected status facet %v", f.Status)
+	}
+	// Decade counts ignore the year range but honour status=read
+	if f.Decade["1810s"] != 1 || f.Decade["1960s"] != 1 || len(f.Decade) != 2 {
+		t.Errorf("Unexpected decade facet %v", f.Decade)
+	}
+	t.Logf("\n🏷️ Facets: status=%v decade=%v", f.Status, f.Decade)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"book-library-backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func importReport(t *testing.T, resp models.APIResponse) models.ImportReport {
+	t.Helper()
+	raw, _ := json.Marshal(resp.Data)
+	var report models.ImportReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("Failed to decode import report: %v", err)
+	}
+	return report
+}
+
+func rowStatuses(report models.ImportReport) string {
+	statuses := make([]string, len(report.Rows))
+	for i, row := range report.Rows {
+		statuses[i] = fmt.Sprintf("%d:%s", row.Row, row.Status)
+	}
+	return fmt.Sprint(statuses)
+}
+
+const csvImport = `Book Title,Writer,Published,Summary
+Dune,Frank Herbert,1965,Desert planet
+"The Great  Gatsby!",f. scott fitzgerald,1925,Already shelved
+Neuromancer,William Gibson,not-a-year,
+,Nobody,2000,
+DUNE,Frank Herbert,1965,Same again
+`
+
+func TestImportCSVWithHeaderMapping(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	headers := map[string]string{"Content-Type": "text/csv"}
+
+	rec, resp := doRequestWithHeaders(t, router, "POST", "/api/books/import?map=Writer:author", csvImport, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	report := importReport(t, resp)
+	if got := rowStatuses(report); got != "[2:created 3:skipped 4:rejected 5:rejected 6:skipped]" {
+		t.Errorf("Unexpected row statuses %s", got)
+	}
+	if report.Created != 1 || report.Skipped != 2 || report.Rejected != 2 || report.Rows[0].ID != 4 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	_, book := doRequest(t, router, "GET", "/api/books/4", "")
+	data := book.Data.(map[string]interface{})
+	if data["title"] != "Dune" || data["status"] != "to-read" || data["description"] != "Desert planet" {
+		t.Errorf("Unexpected imported book %v", data)
+	}
+	t.Logf("\n📥 CSV import report: %s", rowStatuses(report))
+}
+
+func TestImportDryRunCreatesNothing(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/import?dryRun=true", `[
+		{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read"},
+		{"title":"Emma","author":"Jane Austen","year":3000,"status":"read"}
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	report := importReport(t, resp)
+	if !report.DryRun || rowStatuses(report) != "[1:created 2:rejected]" || report.Rows[0].ID != 0 {
+		t.Errorf("Unexpected dry run report %+v", report)
+	}
+
+	_, list := doRequest(t, router, "GET", "/api/books", "")
+	if ids := bookIDs(t, list); len(ids) != 3 {
+		t.Errorf("Expected dry run to leave the library untouched, got %v", ids)
+	}
+}
+
+func TestImportRejectsBadDocuments(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	csvHeaders := map[string]string{"Content-Type": "text/csv"}
+
+	cases := []struct {
+		name    string
+		target  string
+		body    string
+		headers map[string]string
+		status  int
+	}{
+		{"missing column", "/api/books/import", "title,year\nDune,1965\n", csvHeaders, http.StatusBadRequest},
+		{"empty csv", "/api/books/import", "title,author,year\n", csvHeaders, http.StatusBadRequest},
+		{"bad mapping", "/api/books/import?map=Writer:publisher", csvImport, csvHeaders, http.StatusBadRequest},
+		{"bad json", "/api/books/import", `{"title":"x"}`, map[string]string{"Content-Type": "application/json"}, http.StatusBadRequest},
+		{"trailing json", "/api/books/import", `[{"title":"Dune","author":"Frank Herbert","year":1965}] []`, map[string]string{"Content-Type": "application/json"}, http.StatusBadRequest},
+		{"wrong type", "/api/books/import", "<books/>", map[string]string{"Content-Type": "application/xml"}, http.StatusUnsupportedMediaType},
+	}
+	for _, c := range cases {
+		if rec, _ := doRequestWithHeaders(t, router, "POST", c.target, c.body, c.headers); rec.Code != c.status {
+			t.Errorf("%s: expected %d, got %d", c.name, c.status, rec.Code)
+		}
+	}
+}
+
+func TestImportJSONRejectsBadRecords(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books/import", `[
+		{"title":"Dune","author":"Frank Herbert","year":1965},
+		{"title":"Emma","author":"Jane Austen","year":1815,"publisher":"John Murray"},
+		{"title":"Ulysses","author":"James Joyce","year":"1922"},
+		"Neuromancer"
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	report := importReport(t, resp)
+	if got := rowStatuses(report); got != "[1:created 2:rejected 3:rejected 4:rejected]" {
+		t.Fatalf("Unexpected row statuses %s", got)
+	}
+	if errs := report.Rows[1].Errors; len(errs) == 0 || !strings.Contains(errs[0], `"publisher"`) {
+		t.Errorf("Expected the unknown field to be reported, got %v", errs)
+	}
+	if errs := report.Rows[2].Errors; len(errs) == 0 || !strings.Contains(errs[0], "year") {
+		t.Errorf("Expected the mistyped year to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		input, want string
+		valid       bool
+	}{
+		{"0-306-40615-2", "9780306406157", true},
+		{"978-0-306-40615-7", "9780306406157", true},
+		{"080442957x", "9780804429573", true},
+		{"9780804429573", "9780804429573", true},
+		{"", "", true},
+		{"0-306-40615-3", "", false},
+		{"978-0-306-40615-8", "", false},
+		{"X306406152", "", false},
+		{"12345", "", false},
+	}
+	for _, c := range cases {
+		got, err := utils.NormalizeISBN(c.input)
+		if (err == nil) != c.valid || got != c.want {
+			t.Errorf("NormalizeISBN(%q) = %q, %v; want %q (valid %v)", c.input, got, err, c.want, c.valid)
+		}
+	}
+}
+
+func TestISBNLookupAndConflicts(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books",
+		`{"title":"Dune","author":"Frank Herbert","isbn":"0-441-17271-7","year":1965,"status":"read"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if isbn := resp.Data.(map[string]interface{})["isbn"]; isbn != "9780441172719" {
+		t.Errorf("Expected ISBN normalized to 9780441172719, got %v", isbn)
+	}
+
+	rec, resp = doRequest(t, router, "GET", "/api/books/isbn/978-0-441-17271-9", "")
+	if rec.Code != http.StatusOK || resp.Data.(map[string]interface{})["title"] != "Dune" {
+		t.Errorf("Expected Dune by ISBN, got %d %v", rec.Code, resp.Data)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/isbn/9780000000002", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown ISBN, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/isbn/not-an-isbn", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid ISBN, got %d", rec.Code)
+	}
+
+	// A matching ISBN conflicts even when forced
+	rec, resp = doRequest(t, router, "POST", "/api/books?force=true",
+		`{"title":"Dune (reissue)","author":"Frank Herbert","isbn":"9780441172719","year":1990,"status":"read"}`)
+	if rec.Code != http.StatusConflict || resp.Data.(map[string]interface{})["existing_id"] != float64(4) {
+		t.Errorf("Expected 409 pointing at book 4, got %d %v", rec.Code, resp.Data)
+	}
+
+	rec, resp = doRequest(t, router, "PUT", "/api/books/1",
+		`{"title":"To Kill a Mockingbird","author":"Harper Lee","isbn":"0441172717","year":1960,"status":"read"}`)
+	if rec.Code != http.StatusConflict || resp.Error != constants.ErrDuplicateISBN {
+		t.Errorf("Expected 409 %q, got %d %q", constants.ErrDuplicateISBN, rec.Code, resp.Error)
+	}
+
+	if rec, _ := doRequest(t, router, "POST", "/api/books",
+		`{"title":"Emma","author":"Jane Austen","isbn":"123","year":1815,"status":"read"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid ISBN, got %d", rec.Code)
+	}
+	t.Logf("\n🔖 ISBN 0-441-17271-7 stored as 9780441172719")
+}
+
+func TestStoresKeepISBNsUnique(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		book, err := store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", ISBN: "0441172717", Year: 1965, Status: "read"})
+		if err != nil || book.ISBN != "9780441172719" {
+			t.Fatalf("%s: expected normalized ISBN, got %v (%v)", name, book, err)
+		}
+		if found, err := store.FindByISBN(ctx, "9780441172719"); err != nil || found.ID != book.ID {
+			t.Errorf("%s: expected to find book %d by ISBN, got %v (%v)", name, book.ID, found, err)
+		}
+
+		// Trashed books keep their ISBN
+		store.Delete(ctx, book.ID, database.AnyVersion)
+		if _, err := store.FindByISBN(ctx, "9780441172719"); err == nil {
+			t.Errorf("%s: expected trashed book to be hidden from ISBN lookup", name)
+		}
+		_, err = store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "F. Herbert", ISBN: "978-0-441-17271-9", Year: 1965, Status: "read"})
+		if err == nil || err.Error() != constants.ErrDuplicateISBN {
+			t.Errorf("%s: expected duplicate ISBN error, got %v", name, err)
+		}
+
+		// Updating a book without changing its ISBN is fine
+		other, _ := store.Create(ctx, models.CreateBookRequest{Title: "Emma", Author: "Jane Austen", ISBN: "9780141439587", Year: 1815, Status: "read"})
+		if _, err := store.Update(ctx, other.ID, models.UpdateBookRequest{Title: "Emma", Author: "Jane Austen", ISBN: "9780141439587", Year: 1816, Status: "read"}, database.AnyVersion); err != nil {
+			t.Errorf("%s: expected update keeping the ISBN to succeed, got %v", name, err)
+		}
+		updated, err := store.Update(ctx, other.ID, models.UpdateBookRequest{Title: "Emma", Author: "Jane Austen", Year: 1815, Status: "read"}, database.AnyVersion)
+		if err != nil || updated.ISBN != "9780141439587" {
+			t.Errorf("%s: expected an update without ISBN to keep it, got %v (%v)", name, updated, err)
+		}
+	}
+}
+
+func TestPutWithoutISBNKeepsIt(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequest(t, router, "POST", "/api/books",
+		`{"title":"Dune","author":"Frank Herbert","isbn":"0441172717","year":1965,"status":"read"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	id := int(resp.Data.(map[string]interface{})["id"].(float64))
+
+	rec, resp = doRequest(t, router, "PUT", fmt.Sprintf("/api/books/%d", id),
+		`{"title":"Dune","author":"Frank Herbert","year":1965,"description":"Arrakis","status":"read"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if isbn := resp.Data.(map[string]interface{})["isbn"]; isbn != "9780441172719" {
+		t.Errorf("Expected the PUT without isbn to keep 9780441172719, got %v", isbn)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/isbn/9780441172719", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected the book to still be found by ISBN, got %d", rec.Code)
+	}
+}
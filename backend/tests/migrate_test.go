@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsDryRunThenApply(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "library.db")
+
+	pending, err := database.PlanSQLMigrations(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pending) == 0 || pending[0].Version != 1 {
+		t.Fatalf("Expected pending migrations starting at 1, got %+v", pending)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected the dry run not to create the database, got %v", err)
+	}
+
+	store := newSQLTestStore(t, path)
+	store.Close()
+
+	pending, err = database.PlanSQLMigrations(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending migrations after boot, got %d", len(pending))
+	}
+	t.Logf("\n🧱 Migrations planned, applied and up to date")
+}
+
+func TestMigrationsRefuseNewerSchema(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "library.db")
+	newSQLTestStore(t, path).Close()
+
+	db := mustOpen(t, path)
+	_, err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, '9999_future', CURRENT_TIMESTAMP)")
+	if err != nil {
+		t.Fatalf("Failed to insert future migration: %v", err)
+	}
+
+	_, err = database.OpenSQLStore(path)
+	var ahead *database.SchemaAheadError
+	if !errors.As(err, &ahead) {
+		t.Fatalf("Expected SchemaAheadError, got %v", err)
+	}
+	if ahead.DatabaseVersion != 9999 {
+		t.Errorf("Expected database version 9999, got %d", ahead.DatabaseVersion)
+	}
+
+	if _, err := database.Migrate(context.Background(), db, true); !errors.As(err, &ahead) {
+		t.Errorf("Expected dry run to refuse too, got %v", err)
+	}
+	t.Logf("\n⛔ Refused to start: %v", ahead)
+}
+
+func mustOpen(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
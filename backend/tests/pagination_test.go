@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newPagedRouter returns a router over the 3 seeded books plus extra ones.
+func newPagedRouter(t *testing.T, extra int) http.Handler {
+	t.Helper()
+	store := newTestStore(t)
+	for i := 0; i < extra; i++ {
+		_, err := store.Create(context.Background(), models.CreateBookRequest{
+			Title: fmt.Sprintf("Paged %02d", i), Author: "Pager", Year: 2000 + i, Status: "to-read",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+	}
+	return newTestRouterFor(t, store)
+}
+
+func bookIDs(t *testing.T, resp models.APIResponse) []int {
+	t.Helper()
+	items, ok := resp.Data.([]interface{})
+	if !ok {
+		t.Fatalf("Expected a list of books, got %T", resp.Data)
+	}
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, int(item.(map[string]interface{})["id"].(float64)))
+	}
+	return ids
+}
+
+func TestPaginationLimitOffset(t *testing.T) {
+	t.Parallel()
+	router := newPagedRouter(t, 7)
+
+	rec, resp := doRequest(t, router, "GET", "/api/books?limit=4&offset=4", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[5 6 7 8]" {
+		t.Errorf("Expected books [5 6 7 8], got %v", ids)
+	}
+	p := resp.Pagination
+	if p == nil || p.Total != 10 || p.Limit != 4 || p.Offset != 4 {
+		t.Fatalf("Unexpected pagination: %+v", p)
+	}
+	if p.Next != "/api/books?limit=4&offset=8" || p.Prev != "/api/books?limit=4&offset=0" {
+		t.Errorf("Unexpected links next=%q prev=%q", p.Next, p.Prev)
+	}
+	t.Logf("\n📄 Page: %+v", p)
+}
+
+func TestPaginationCursorWalk(t *testing.T) {
+	t.Parallel()
+	router := newPagedRouter(t, 7)
+
+	seen := []int{}
+	target := "/api/books?limit=3"
+	for pages := 0; target != ""; pages++ {
+		if pages > 10 {
+			t.Fatalf("Cursor walk did not terminate")
+		}
+		rec, resp := doRequest(t, router, "GET", target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		seen = append(seen, bookIDs(t, resp)...)
+		target = ""
+		if resp.Pagination.NextCursor != "" {
+			target = "/api/books?cursor=" + resp.Pagination.NextCursor
+		}
+	}
+	if fmt.Sprint(seen) != "[1 2 3 4 5 6 7 8 9 10]" {
+		t.Errorf("Expected every book exactly once, got %v", seen)
+	}
+	t.Logf("\n🧭 Cursor walk visited %v", seen)
+}
+
+func TestPaginationCursorIsKeyset(t *testing.T) {
+	t.Parallel()
+	router := newPagedRouter(t, 7)
+
+	// Sorted by descending year: 9 8 7 6 5 4 3 then the seeded books
+	_, first := doRequest(t, router, "GET", "/api/books?limit=3&sort=-year", "")
+	if ids := bookIDs(t, first); fmt.Sprint(ids) != "[10 9 8]" {
+		t.Fatalf("Expected books [10 9 8], got %v", ids)
+	}
+
+	// Removing a book already seen must not shift the next page
+	if rec, _ := doRequest(t, router, "DELETE", "/api/books/9", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the delete to succeed, got %d", rec.Code)
+	}
+	rec, second := doRequest(t, router, "GET", "/api/books?sort=-year&cursor="+first.Pagination.NextCursor, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ids := bookIDs(t, second); fmt.Sprint(ids) != "[7 6 5]" {
+		t.Errorf("Expected the page after book 8 to be [7 6 5], got %v", ids)
+	}
+
+	// The previous cursor leads back to the books before book 7
+	_, back := doRequest(t, router, "GET", "/api/books?sort=-year&cursor="+second.Pagination.PrevCursor, "")
+	if ids := bookIDs(t, back); fmt.Sprint(ids) != "[10 8]" {
+		t.Errorf("Expected the page before book 7 to be [10 8], got %v", ids)
+	}
+
+	// A cursor only makes sense for the order it was issued for
+	if rec, _ := doRequest(t, router, "GET", "/api/books?sort=title&cursor="+first.Pagination.NextCursor, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a cursor reused with another sort, got %d", rec.Code)
+	}
+	t.Logf("\n🧭 Keyset pages %v then %v", bookIDs(t, first), bookIDs(t, second))
+}
+
+func TestPaginationRejectsBadParams(t *testing.T) {
+	t.Parallel()
+	router := newPagedRouter(t, 0)
+
+	for _, target := range []string{
+		"/api/books?limit=0",
+		"/api/books?limit=1000",
+		"/api/books?offset=-1",
+		"/api/books?cursor=not-a-cursor",
+		"/api/books?cursor=eyJvIjowLCJsIjoyfQ&offset=2",
+	} {
+		if rec, _ := doRequest(t, router, "GET", target, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestPaginationDefaultsToFullList(t *testing.T) {
+	t.Parallel()
+	router := newPagedRouter(t, 2)
+
+	_, resp := doRequest(t, router, "GET", "/api/books", "")
+	if ids := bookIDs(t, resp); len(ids) != 5 {
+		t.Errorf("Expected all 5 books without paging params, got %v", ids)
+	}
+	if resp.Pagination == nil || resp.Pagination.Total != 5 {
+		t.Errorf("Expected total 5, got %+v", resp.Pagination)
+	}
+}
+
+func TestPaginationStoresAgree(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	titles := []string{"apple", "Apple", "Émile", "émile", "banana", "Zebra", "zebra", "Apple"}
+	statuses := []string{"read", "to-read", "reading"}
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		for i, title := range titles {
+			_, err := store.Create(ctx, models.CreateBookRequest{
+				Title: title, Author: fmt.Sprintf("Author %c", 'c'-i%3), Year: 1990 + i%4*5, Status: statuses[i%3],
+			})
+			if err != nil {
+				t.Fatalf("%s: failed to create book: %v", name, err)
+			}
+		}
+		if err := store.Delete(ctx, 5, database.AnyVersion); err != nil {
+			t.Fatalf("%s: failed to delete book: %v", name, err)
+		}
+	}
+
+	cases := []struct {
+		query  string
+		filter models.BookFilter
+		sort   []models.SortKey
+	}{
+		{"sort=title", models.BookFilter{}, []models.SortKey{{Field: "title"}, {Field: "id"}}},
+		{"sort=-title,author", models.BookFilter{}, []models.SortKey{{Field: "title", Desc: true}, {Field: "author"}, {Field: "id"}}},
+		{"sort=-year,status", models.BookFilter{}, []models.SortKey{{Field: "year", Desc: true}, {Field: "status"}, {Field: "id"}}},
+		{"sort=-created_at", models.BookFilter{}, []models.SortKey{{Field: "created_at", Desc: true}, {Field: "id"}}},
+		{"sort=author,-id&status=read,reading", models.BookFilter{Statuses: map[string]bool{"read": true, "reading": true}},
+			[]models.SortKey{{Field: "author"}, {Field: "id", Desc: true}}},
+		{"title=%C3%A9mi&yearFrom=1995", models.BookFilter{Title: "émi", YearFrom: 1995}, []models.SortKey{{Field: "id"}}},
+	}
+
+	for name, store := range stores {
+		router := newTestRouterFor(t, store)
+		all, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("%s: failed to list books: %v", name, err)
+		}
+
+		for _, c := range cases {
+			var matching []*models.Book
+			for _, book := range all {
+				if c.filter.Matches(book) {
+					matching = append(matching, book)
+				}
+			}
+			sort.SliceStable(matching, func(i, j int) bool { return models.CompareBooks(matching[i], matching[j], c.sort) < 0 })
+			want := make([]int, 0, len(matching))
+			for _, book := range matching {
+				want = append(want, book.ID)
+			}
+
+			// Walk forward by cursor, then back from the last page
+			var forward []int
+			var pages []models.APIResponse
+			target := "/api/books?limit=3&" + c.query
+			for target != "" && len(pages) < 10 {
+				rec, resp := doRequest(t, router, "GET", target, "")
+				if rec.Code != http.StatusOK {
+					t.Fatalf("%s %s: expected status 200, got %d: %s", name, c.query, rec.Code, rec.Body.String())
+				}
+				if resp.Pagination.Offset != len(forward) || resp.Pagination.Total != len(want) {
+					t.Errorf("%s %s: unexpected pagination %+v after %d books", name, c.query, resp.Pagination, len(forward))
+				}
+				forward = append(forward, bookIDs(t, resp)...)
+				pages = append(pages, resp)
+				target = ""
+				if resp.Pagination.NextCursor != "" {
+					target = "/api/books?" + c.query + "&cursor=" + resp.Pagination.NextCursor
+				}
+			}
+			if fmt.Sprint(forward) != fmt.Sprint(want) {
+				t.Errorf("%s %s: expected %v, walked %v", name, c.query, want, forward)
+			}
+
+			backward := bookIDs(t, pages[len(pages)-1])
+			for prev := pages[len(pages)-1].Pagination.PrevCursor; prev != ""; {
+				_, resp := doRequest(t, router, "GET", "/api/books?"+c.query+"&cursor="+prev, "")
+				backward = append(bookIDs(t, resp), backward...)
+				prev = resp.Pagination.PrevCursor
+			}
+			if fmt.Sprint(backward) != fmt.Sprint(want) {
+				t.Errorf("%s %s: expected %v walking back, got %v", name, c.query, want, backward)
+			}
+
+			if facets := models.CountFacets(all, c.filter); !reflect.DeepEqual(pages[0].Facets, facets) {
+				t.Errorf("%s %s: expected facets %+v, got %+v", name, c.query, facets, pages[0].Facets)
+			}
+			t.Logf("\n📚 %s %s: %v", name, c.query, forward)
+		}
+
+		// Offsets page the same order
+		_, resp := doRequest(t, router, "GET", "/api/books?sort=title&limit=4&offset=6", "")
+		if ids := bookIDs(t, resp); len(ids) != 4 || resp.Pagination.Offset != 6 {
+			t.Errorf("%s: expected 4 books at offset 6, got %v (%+v)", name, ids, resp.Pagination)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"book-library-backend/utils"
+	"net/http"
+	"testing"
+)
+
+func mergePatchHeaders() map[string]string {
+	return map[string]string{"Content-Type": utils.MergePatchContentType}
+}
+
+func TestPatchMergeUpdatesOnlyGivenFields(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	rec, resp := doRequestWithHeaders(t, router, "PATCH", "/api/books/2", `{"status":"read"}`, mergePatchHeaders())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	book := resp.Data.(map[string]interface{})
+	if book["status"] != "read" || book["title"] != "1984" || book["author"] != "George Orwell" {
+		t.Errorf("Unexpected patched book %v", book)
+	}
+	t.Logf("\n🩹 Merge patch set status=%v on %v", book["status"], book["title"])
+}
+
+func TestPatchMergeNullRemovesOptionalField(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	_, resp := doRequestWithHeaders(t, router, "PATCH", "/api/books/1", `{"description":null}`, mergePatchHeaders())
+	if description := resp.Data.(map[string]interface{})["description"]; description != "" {
+		t.Errorf("Expected description to be cleared, got %v", description)
+	}
+}
+
+func TestPatchMergeValidatesResult(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	for _, body := range []string{
+		`{"title":null}`,
+		`{"year":99999}`,
+		`{"status":"lost"}`,
+		`{"auther":"typo"}`,
+		`{"id":42}`,
+		`{"year":"nineteen"}`,
+	} {
+		if rec, _ := doRequestWithHeaders(t, router, "PATCH", "/api/books/1", body, mergePatchHeaders()); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestPatchJSONPatch(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+	headers := map[string]string{"Content-Type": utils.JSONPatchContentType}
+
+	rec, resp := doRequestWithHeaders(t, router, "PATCH", "/api/books/3", `[
+		{"op":"test","path":"/status","value":"to-read"},
+		{"op":"replace","path":"/status","value":"reading"},
+		{"op":"copy","from":"/author","path":"/description"}
+	]`, headers)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	book := resp.Data.(map[string]interface{})
+	if book["status"] != "reading" || book["description"] != "F. Scott Fitzgerald" {
+		t.Errorf("Unexpected patched book %v", book)
+	}
+
+	rec, _ = doRequestWithHeaders(t, router, "PATCH", "/api/books/3",
+		`[{"op":"test","path":"/status","value":"to-read"},{"op":"replace","path":"/status","value":"read"}]`, headers)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected failed test op to return 400, got %d", rec.Code)
+	}
+}
+
+func TestPatchErrors(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	if rec, _ := doRequestWithHeaders(t, router, "PATCH", "/api/books/9999", `{"status":"read"}`, mergePatchHeaders()); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+	if rec, _ := doRequestWithHeaders(t, router, "PATCH", "/api/books/1", `status=read`, map[string]string{"Content-Type": "text/plain"}); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415, got %d", rec.Code)
+	}
+}
+
+func TestApplyJSONPatchArrays(t *testing.T) {
+	t.Parallel()
+	out, err := utils.ApplyJSONPatch([]byte(`{"tags":["a","c"]}`), []byte(`[
+		{"op":"add","path":"/tags/1","value":"b"},
+		{"op":"add","path":"/tags/-","value":"d"},
+		{"op":"remove","path":"/tags/0"},
+		{"op":"move","from":"/tags","path":"/labels"}
+	]`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(out) != `{"labels":["b","c","d"]}` {
+		t.Errorf("Unexpected document %s", out)
+	}
+}
@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/middleware"
+	"book-library-backend/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// fakeClock is a settable time source shared with a RateLimiter
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// rateLimitTestKeys are the API keys accepted by newRateLimitTestRouter
+var rateLimitTestKeys = map[string]models.Principal{"ci-key": {Subject: "ci-bot"}}
+
+// newRateLimitTestRouter places the limiter around Authenticate, as main.go
+// does, accepting rateLimitTestKeys
+func newRateLimitTestRouter(t *testing.T, config middleware.RateLimiterConfig) (http.Handler, *middleware.RateLimiter) {
+	t.Helper()
+	limiter := middleware.NewRateLimiter(config)
+	authenticator := auth.NewAuthenticator(auth.Config{APIKeys: rateLimitTestKeys})
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	router.Use(limiter.AuthFailures)
+	router.Use(middleware.Authenticate(authenticator, "/api/books"))
+	router.Use(limiter.Middleware)
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/books", ok).Methods("GET")
+	api.HandleFunc("/books", ok).Methods("POST")
+	api.HandleFunc("/books/{id}", ok).Methods("GET")
+	return router, limiter
+}
+
+// rateLimitedRequest sends method target from remoteIP with the given headers
+func rateLimitedRequest(router http.Handler, method, target, remoteIP string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(""))
+	req.RemoteAddr = remoteIP + ":40000"
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitPerClientAndRoute(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	router, _ := newRateLimitTestRouter(t, middleware.RateLimiterConfig{
+		Default: middleware.RateLimit{Rate: 10, Burst: 10},
+		Routes:  map[string]middleware.RateLimit{"POST /api/books": {Rate: 1.0 / 60, Burst: 2}},
+		Now:     clock.Now,
+	})
+
+	for i, want := range []string{"1", "0"} {
+		rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.1", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != want || rec.Header().Get("X-RateLimit-Limit") != "2" {
+			t.Errorf("Request %d: expected 200 with %s remaining, got %d %v", i+1, want, rec.Code, rec.Header())
+		}
+	}
+
+	rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.1", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" || rec.Header().Get("X-RateLimit-Reset") != "120" {
+		t.Errorf("Expected 429 with Retry-After 60 and reset 120, got %d %v", rec.Code, rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), constants.CodeRateLimited) {
+		t.Errorf("Expected code %q, got %s", constants.CodeRateLimited, rec.Body.String())
+	}
+
+	// Other routes, other clients and authenticated subjects have buckets of
+	// their own
+	if rec := rateLimitedRequest(router, "GET", "/api/books", "192.0.2.1", nil); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "10" {
+		t.Errorf("Expected the default limit on GET /api/books, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.2", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to be served, got %d", rec.Code)
+	}
+	withKey := map[string]string{auth.APIKeyHeader: "ci-key"}
+	if rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.1", withKey); rec.Code != http.StatusOK {
+		t.Errorf("Expected an authenticated subject to get its own bucket, got %d", rec.Code)
+	}
+
+	// Tokens come back at the configured rate
+	clock.Advance(time.Minute)
+	if rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.1", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected a token after a minute, got %d", rec.Code)
+	}
+	if rec := rateLimitedRequest(router, "POST", "/api/books", "192.0.2.1", nil); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected only one token after a minute, got %d", rec.Code)
+	}
+	t.Logf("\n🚦 POST /api/books throttled to 2 requests then 1 per minute")
+}
+
+func TestRateLimitSharesBucketsAcrossPathParameters(t *testing.T) {
+	t.Parallel()
+	router, _ := newRateLimitTestRouter(t, middleware.RateLimiterConfig{Default: middleware.RateLimit{Rate: 0.1, Burst: 1}})
+
+	withKey := map[string]string{auth.APIKeyHeader: "ci-key"}
+	if rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.1", withKey); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if rec := rateLimitedRequest(router, "GET", "/api/books/2", "192.0.2.1", withKey); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected /api/books/{id} to share one bucket, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitEvictsIdleBuckets(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	router, limiter := newRateLimitTestRouter(t, middleware.RateLimiterConfig{
+		Default:     middleware.RateLimit{Rate: 1, Burst: 5},
+		IdleTimeout: time.Minute,
+		Now:         clock.Now,
+	})
+
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		rateLimitedRequest(router, "GET", "/api/books", ip, nil)
+	}
+	if n := limiter.Buckets(); n != 3 {
+		t.Fatalf("Expected 3 buckets, got %d", n)
+	}
+
+	clock.Advance(2 * time.Minute)
+	rateLimitedRequest(router, "GET", "/api/books", "192.0.2.4", nil)
+	if n := limiter.Buckets(); n != 1 {
+		t.Errorf("Expected idle buckets to be evicted, got %d", n)
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	t.Parallel()
+	limit, err := middleware.ParseRateLimit("30/m")
+	if err != nil || limit.Burst != 30 || limit.Rate != 0.5 {
+		t.Errorf("Expected 30 per minute, got %+v (%v)", limit, err)
+	}
+	if limit, err := middleware.ParseRateLimit("off"); err != nil || limit.Enabled() {
+		t.Errorf("Expected off to disable limiting, got %+v (%v)", limit, err)
+	}
+	for _, spec := range []string{"", "30", "0/m", "30/d", "x/s"} {
+		if _, err := middleware.ParseRateLimit(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+
+	routes, err := middleware.ParseRouteRateLimits("post /api/books=10/s, GET /api/books/{id}=off")
+	if err != nil || routes["POST /api/books"].Burst != 10 || routes["GET /api/books/{id}"].Enabled() {
+		t.Errorf("Unexpected route limits %+v (%v)", routes, err)
+	}
+	if _, err := middleware.ParseRouteRateLimits("/api/books=10/s"); err == nil {
+		t.Errorf("Expected an entry without a method to be rejected")
+	}
+}
+
+func TestRateLimitIgnoresUnverifiedKeys(t *testing.T) {
+	t.Parallel()
+	limiter := middleware.NewRateLimiter(middleware.RateLimiterConfig{Default: middleware.RateLimit{Rate: 1.0 / 60, Burst: 1}})
+	router := mux.NewRouter()
+	router.Use(limiter.Middleware)
+	router.HandleFunc("/api/books", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	served := 0
+	for i := 0; i < 50; i++ {
+		junk := map[string]string{auth.APIKeyHeader: fmt.Sprintf("junk-%d", i)}
+		if rec := rateLimitedRequest(router, "GET", "/api/books", "192.0.2.1", junk); rec.Code == http.StatusOK {
+			served++
+		}
+	}
+	if served != 1 || limiter.Buckets() != 1 {
+		t.Errorf("Expected junk keys to share the bucket of their IP, served %d with %d buckets", served, limiter.Buckets())
+	}
+	t.Logf("\n🚦 50 junk keys from one IP: %d served, %d bucket", served, limiter.Buckets())
+}
+
+func TestRateLimitCapsBuckets(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	router, limiter := newRateLimitTestRouter(t, middleware.RateLimiterConfig{
+		Default:    middleware.RateLimit{Rate: 1, Burst: 5},
+		MaxBuckets: 2,
+		Now:        clock.Now,
+	})
+
+	rateLimitedRequest(router, "GET", "/api/books", "192.0.2.1", nil)
+	rateLimitedRequest(router, "GET", "/api/books", "192.0.2.2", nil)
+	if rec := rateLimitedRequest(router, "GET", "/api/books", "192.0.2.3", nil); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a new client to be throttled while the limiter is full, got %d", rec.Code)
+	}
+	if rec := rateLimitedRequest(router, "GET", "/api/books", "192.0.2.1", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected known clients to keep being served, got %d", rec.Code)
+	}
+
+	// Buckets that have refilled make room without waiting for the idle timeout
+	clock.Advance(10 * time.Second)
+	if rec := rateLimitedRequest(router, "GET", "/api/books", "192.0.2.3", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected refilled buckets to be dropped for a new client, got %d", rec.Code)
+	}
+	if n := limiter.Buckets(); n > 2 {
+		t.Errorf("Expected at most 2 buckets, got %d", n)
+	}
+}
+
+func TestRateLimitThrottlesFailedAuthentications(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	router, _ := newRateLimitTestRouter(t, middleware.RateLimiterConfig{
+		AuthFailures: middleware.RateLimit{Rate: 1.0 / 60, Burst: 3},
+		Now:          clock.Now,
+	})
+	valid := map[string]string{auth.APIKeyHeader: "ci-key"}
+
+	// Successful authentications cost nothing
+	for i := 0; i < 10; i++ {
+		if rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.1", valid); rec.Code != http.StatusOK {
+			t.Fatalf("Expected valid requests to be served, got %d", rec.Code)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		guess := map[string]string{auth.APIKeyHeader: fmt.Sprintf("guess-%d", i)}
+		if rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.1", guess); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected guess %d to be answered with 401, got %d", i, rec.Code)
+		}
+	}
+	rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.1", map[string]string{auth.APIKeyHeader: "guess-3"})
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected 429 with Retry-After 60 once the failures are spent, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.2", valid); rec.Code != http.StatusOK {
+		t.Errorf("Expected other IPs to be unaffected, got %d", rec.Code)
+	}
+
+	clock.Advance(time.Minute)
+	if rec := rateLimitedRequest(router, "GET", "/api/books/1", "192.0.2.1", valid); rec.Code != http.StatusOK {
+		t.Errorf("Expected the IP to be served again once a token refilled, got %d", rec.Code)
+	}
+	t.Logf("\n🚦 3 failed authentications allowed per IP before 429")
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/middleware"
+	"book-library-backend/models"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newRBACTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	keys, err := auth.ParseAPIKeys("root:admin-key:admin, lib:librarian-key:librarian, ann:reader-key:reader, bare:plain-key")
+	if err != nil {
+		t.Fatalf("Failed to parse API keys: %v", err)
+	}
+	return newTestRouterWith(t, newTestStore(t), auth.NewAuthenticator(auth.Config{APIKeys: keys}))
+}
+
+func TestRolesGuardCatalogMutations(t *testing.T) {
+	t.Parallel()
+	book := `{"title":"Dune","author":"Frank Herbert","year":1965,"status":"read"}`
+	csvBody := "title,author,year,status\nEmma,Jane Austen,1815,read\n"
+	requests := []struct {
+		name, method, target, body, contentType string
+	}{
+		{"create", "POST", "/api/books", book, "application/json"},
+		{"update", "PUT", "/api/books/1", `{"title":"To Kill a Mockingbird","author":"Harper Lee","year":1960,"status":"read"}`, "application/json"},
+		{"patch", "PATCH", "/api/books/2", `{"status":"read"}`, "application/merge-patch+json"},
+		{"import", "POST", "/api/books/import", csvBody, "text/csv"},
+		{"batch", "POST", "/api/books/batch", `[{"op":"create","book":` + book + `}]`, "application/json"},
+		{"delete", "DELETE", "/api/books/3", "", "application/json"},
+		{"process-url", "POST", "/api/process-url", `{"url":"https://byfood.com/a/","operation":"canonical"}`, "application/json"},
+	}
+	allowed := map[string]map[string]bool{
+		"admin-key":     {"create": true, "update": true, "patch": true, "import": true, "batch": true, "delete": true, "process-url": true},
+		"librarian-key": {"create": true, "update": true, "patch": true, "import": true, "process-url": true},
+		"reader-key":    {"process-url": true},
+		"plain-key":     {"process-url": true},
+	}
+
+	for key, grants := range allowed {
+		// Each key gets a fresh library so deletes do not affect later keys
+		router := newRBACTestRouter(t)
+		for _, req := range requests {
+			headers := map[string]string{"Content-Type": req.contentType, auth.APIKeyHeader: key}
+			rec, resp := doRequestWithHeaders(t, router, req.method, req.target, req.body, headers)
+			if grants[req.name] {
+				if rec.Code == http.StatusForbidden {
+					t.Errorf("%s: expected %s to be allowed, got 403 %q", key, req.name, resp.Error)
+				}
+				continue
+			}
+			if rec.Code != http.StatusForbidden || resp.Code != constants.CodeForbidden {
+				t.Errorf("%s: expected 403 %q for %s, got %d %q", key, constants.CodeForbidden, req.name, rec.Code, resp.Code)
+			}
+		}
+
+		// Reads and personal shelves are open to every role
+		headers := map[string]string{"Content-Type": "application/json", auth.APIKeyHeader: key}
+		if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/books", "", headers); rec.Code != http.StatusOK {
+			t.Errorf("%s: expected listing books to be allowed, got %d", key, rec.Code)
+		}
+		if rec, _ := doRequestWithHeaders(t, router, "PUT", "/api/me/books/1", `{"status":"reading"}`, headers); rec.Code != http.StatusCreated {
+			t.Errorf("%s: expected shelving a book to be allowed, got %d", key, rec.Code)
+		}
+	}
+	t.Logf("\n🛡️ %d routes checked for %d keys", len(requests), len(allowed))
+}
+
+func TestRolesFromTokenClaims(t *testing.T) {
+	t.Parallel()
+	router := newTestRouterWith(t, newTestStore(t), auth.NewAuthenticator(auth.Config{HMACSecret: testJWTSecret}))
+
+	token := tokenFor(t, "ann")
+	rec, resp := doRequestWithHeaders(t, router, "DELETE", "/api/books/1", "", bearer(token))
+	if rec.Code != http.StatusForbidden || !strings.Contains(resp.Error, auth.RoleAdmin) {
+		t.Errorf("Expected 403 naming the admin role for a token without roles, got %d %q", rec.Code, resp.Error)
+	}
+
+	token = signJWT(t, "HS256", map[string]interface{}{
+		"sub":   "root",
+		"exp":   4102444800,
+		"roles": []string{auth.RoleReader, auth.RoleAdmin},
+	}, hs256(testJWTSecret))
+	if rec, resp := doRequestWithHeaders(t, router, "DELETE", "/api/books/1", "", bearer(token)); rec.Code != http.StatusOK {
+		t.Errorf("Expected an admin token to delete, got %d %q", rec.Code, resp.Error)
+	}
+}
+
+func TestPolicyAllows(t *testing.T) {
+	t.Parallel()
+	librarian := &models.Principal{Subject: "lib", Roles: []string{auth.RoleLibrarian}}
+	if !auth.DefaultPolicy.Allows(librarian, auth.ActionCreate) || auth.DefaultPolicy.Allows(librarian, auth.ActionDelete) {
+		t.Errorf("Expected librarians to create but not delete")
+	}
+	if denied := auth.DefaultPolicy.Denied(librarian, auth.RouteActions["POST /api/books/batch"]); denied != auth.ActionDelete {
+		t.Errorf("Expected a batch to be denied for delete, got %q", denied)
+	}
+	if (auth.Policy{}).Allows(&models.Principal{Roles: []string{auth.RoleAdmin}}, auth.ActionCreate) {
+		t.Errorf("Expected actions missing from the policy to be denied")
+	}
+
+	keys, err := auth.ParseAPIKeys("root:k1:admin|librarian")
+	if err != nil || len(keys["k1"].Roles) != 2 {
+		t.Errorf("Expected two roles, got %v (%v)", keys, err)
+	}
+	if _, err := auth.ParseAPIKeys("root:k1:owner"); err == nil {
+		t.Errorf("Expected an unknown role to be rejected")
+	}
+}
+
+func TestEveryMutatingRouteIsGuarded(t *testing.T) {
+	t.Parallel()
+	router, ok := newTestRouter(t).(*mux.Router)
+	if !ok {
+		t.Fatalf("Expected the test router to be a *mux.Router")
+	}
+
+	seen := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			key := method + " " + template
+			seen[key] = true
+			if _, guarded := auth.RouteActions[key]; !guarded && !auth.IsSafeMethod(method) {
+				t.Errorf("%s changes state but has no entry in auth.RouteActions", key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk the router: %v", err)
+	}
+	for key := range auth.RouteActions {
+		if !seen[key] {
+			t.Errorf("auth.RouteActions lists %s, which no route serves", key)
+		}
+	}
+	t.Logf("\n🛡️ %d mutating routes guarded", len(auth.RouteActions))
+}
+
+func TestAuthorizeDeniesUnlistedRoutes(t *testing.T) {
+	t.Parallel()
+	authenticator := auth.NewAuthenticator(auth.Config{APIKeys: map[string]models.Principal{"root-key": {Subject: "root", Roles: []string{auth.RoleAdmin}}}})
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	router.Use(middleware.Authenticate(authenticator))
+	router.Use(middleware.Authorize(authenticator, auth.DefaultPolicy, map[string][]string{}))
+	router.HandleFunc("/api/unlisted", ok).Methods("GET", "POST")
+
+	headers := map[string]string{auth.APIKeyHeader: "root-key"}
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/unlisted", "", headers); rec.Code != http.StatusOK {
+		t.Errorf("Expected an unlisted GET to be served, got %d", rec.Code)
+	}
+	rec, resp := doRequestWithHeaders(t, router, "POST", "/api/unlisted", "", headers)
+	if rec.Code != http.StatusForbidden || resp.Code != constants.CodeForbidden {
+		t.Errorf("Expected 403 %q for an unlisted POST even for admins, got %d %q", constants.CodeForbidden, rec.Code, resp.Code)
+	}
+}
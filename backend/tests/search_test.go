@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"book-library-backend/search"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestTokenizeFoldsAndStems(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"Les Misérables", "[les miserable]"},
+		{"The Stories of Running Dogs", "[story run dog]"},
+		{"Crème brûlée, naïve café!", "[creme brulee naive cafe]"},
+		{"Classes and boxes", "[class box]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprint(search.Tokenize(c.input)); got != c.expected {
+			t.Errorf("Tokenize(%q) = %s, want %s", c.input, got, c.expected)
+		}
+	}
+}
+
+func TestIndexFollowsStoreWrites(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	book, _ := store.Create(ctx, models.CreateBookRequest{
+		Title: "Cien años de soledad", Author: "Gabriel García Márquez", Year: 1967, Status: "to-read",
+	})
+	if results, _ := store.Search(ctx, "garcia"); len(results) != 1 || results[0].ID != book.ID {
+		t.Fatalf("Expected created book to be searchable, got %v", results)
+	}
+
+	_, _ = store.Update(ctx, book.ID, models.UpdateBookRequest{
+		Title: "One Hundred Years of Solitude", Author: "Gabriel García Márquez", Year: 1967, Status: "read",
+	}, database.AnyVersion)
+	if results, _ := store.Search(ctx, "soledad"); len(results) != 0 {
+		t.Errorf("Expected old title to be unindexed, got %d results", len(results))
+	}
+	if results, _ := store.Search(ctx, "solitude"); len(results) != 1 {
+		t.Errorf("Expected new title to be indexed, got %d results", len(results))
+	}
+
+	_ = store.Delete(ctx, book.ID, database.AnyVersion)
+	if results, _ := store.Search(ctx, "solitude"); len(results) != 0 {
+		t.Errorf("Expected deleted book to be unindexed, got %d results", len(results))
+	}
+}
+
+func TestSearchRanksTitleAboveDescription(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	ctx := context.Background()
+	_, _ = store.Create(ctx, models.CreateBookRequest{
+		Title: "Dreams of Gatsby", Author: "Critic", Year: 2001, Description: "An essay.", Status: "to-read",
+	})
+	router := newTestRouterFor(t, store)
+
+	rec, resp := doRequest(t, router, "GET", "/api/books?q=american+dream", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	// Gatsby's description mentions "American Dream"; Mockingbird only "American"
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[3]" {
+		t.Errorf("Expected only book 3 to match every term, got %v", ids)
+	}
+
+	_, resp = doRequest(t, router, "GET", "/api/books?q=gatsby", "")
+	ids := bookIDs(t, resp)
+	if fmt.Sprint(ids) != "[3 4]" {
+		t.Fatalf("Expected both Gatsby titles, got %v", ids)
+	}
+	items := resp.Data.([]interface{})
+	first := items[0].(map[string]interface{})["score"].(float64)
+	second := items[1].(map[string]interface{})["score"].(float64)
+	if first < second || second <= 0 {
+		t.Errorf("Expected descending positive scores, got %v then %v", first, second)
+	}
+	t.Logf("\n🔍 q=gatsby → %v (scores %.3f, %.3f)", ids, first, second)
+}
+
+func TestSQLStoreSearchAfterRestart(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "library.db")
+	ctx := context.Background()
+
+	store := newSQLTestStore(t, path)
+	_, _ = store.Create(ctx, models.CreateBookRequest{
+		Title: "Le Petit Prince", Author: "Antoine de Saint-Exupéry", Year: 1943, Status: "read",
+	})
+	store.Close()
+
+	reopened := newSQLTestStore(t, path)
+	defer reopened.Close()
+	results, err := reopened.Search(ctx, "exupery prince")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Le Petit Prince" {
+		t.Errorf("Expected rebuilt index to find the book, got %v", results)
+	}
+}
+
+func TestSQLStoreSearchManyHits(t *testing.T) {
+	t.Parallel()
+	store := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer store.Close()
+	ctx := context.Background()
+
+	ops := make([]models.BatchOperation, 1200)
+	for i := range ops {
+		ops[i] = models.BatchOperation{Op: "create", Book: models.CreateBookRequest{
+			Title: fmt.Sprintf("Saga Volume %d", i+1), Author: "Serial Author", Year: 2000, Status: "unread",
+		}}
+	}
+	if _, err := store.ApplyBatch(ctx, ops, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	results, err := store.Search(ctx, "saga")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != len(ops) {
+		t.Errorf("Expected %d results, got %d", len(ops), len(results))
+	}
+}
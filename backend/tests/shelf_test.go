@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"book-library-backend/auth"
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newShelfTestRouter requires bearer tokens signed with testJWTSecret
+func newShelfTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	return newTestRouterWith(t, newTestStore(t), auth.NewAuthenticator(auth.Config{HMACSecret: testJWTSecret}))
+}
+
+// shelfRequest sends a JSON request as actor, authenticated with a bearer token
+func shelfRequest(t *testing.T, router http.Handler, actor, method, target, body string) (int, models.APIResponse) {
+	t.Helper()
+	rec, resp := doRequestWithHeaders(t, router, method, target, body, bearer(tokenFor(t, actor)))
+	return rec.Code, resp
+}
+
+func TestShelfLifecycle(t *testing.T) {
+	t.Parallel()
+	router := newShelfTestRouter(t)
+
+	code, resp := shelfRequest(t, router, "alice", "PUT", "/api/me/books/2", `{"status":"reading","notes":"Big Brother"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %+v", code, resp)
+	}
+	item := resp.Data.(map[string]interface{})
+	if item["started_at"] == nil || item["finished_at"] != nil {
+		t.Errorf("Expected started_at to be stamped when reading, got %v", item)
+	}
+	if item["book"].(map[string]interface{})["title"] != "1984" {
+		t.Errorf("Expected the catalog book to be embedded, got %v", item["book"])
+	}
+
+	code, resp = shelfRequest(t, router, "alice", "PUT", "/api/me/books/2", `{"status":"read"}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200 on update, got %d: %+v", code, resp)
+	}
+	if item := resp.Data.(map[string]interface{}); item["finished_at"] == nil || item["started_at"] == nil {
+		t.Errorf("Expected both dates after finishing, got %v", item)
+	}
+
+	shelfRequest(t, router, "alice", "PUT", "/api/me/books/3", `{"status":"to-read"}`)
+	code, resp = shelfRequest(t, router, "alice", "GET", "/api/me/books?status=read", "")
+	if code != http.StatusOK || resp.Pagination.Total != 1 {
+		t.Errorf("Expected one read book on the shelf, got %d %+v", code, resp.Pagination)
+	}
+
+	// The catalog status is not touched by shelf updates
+	_, resp = shelfRequest(t, router, "alice", "GET", "/api/books/3", "")
+	if resp.Data.(map[string]interface{})["status"] != "to-read" {
+		t.Errorf("Expected catalog status to stay to-read, got %v", resp.Data)
+	}
+
+	if code, _ := shelfRequest(t, router, "alice", "DELETE", "/api/me/books/3", ""); code != http.StatusOK {
+		t.Errorf("Expected status 200 on removal, got %d", code)
+	}
+	if code, resp := shelfRequest(t, router, "alice", "GET", "/api/me/books/3", ""); code != http.StatusNotFound || resp.Code != constants.CodeUserBookNotFound {
+		t.Errorf("Expected 404 %s after removal, got %d %s", constants.CodeUserBookNotFound, code, resp.Code)
+	}
+	if code, _ := shelfRequest(t, router, "alice", "GET", "/api/books/3", ""); code != http.StatusOK {
+		t.Errorf("Expected removal to keep the catalog book, got %d", code)
+	}
+	t.Logf("\n📚 alice finished 1984 and shelved then removed The Great Gatsby")
+}
+
+func TestShelfIsPerUser(t *testing.T) {
+	t.Parallel()
+	router := newShelfTestRouter(t)
+
+	shelfRequest(t, router, "alice", "PUT", "/api/me/books/1", `{"status":"read","notes":"mine"}`)
+
+	code, resp := shelfRequest(t, router, "bob", "GET", "/api/me/books", "")
+	if code != http.StatusOK || resp.Pagination.Total != 0 {
+		t.Errorf("Expected bob's shelf to be empty, got %d %+v", code, resp.Pagination)
+	}
+	if code, _ := shelfRequest(t, router, "bob", "GET", "/api/me/books/1", ""); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for alice's book on bob's shelf, got %d", code)
+	}
+	if code, _ := shelfRequest(t, router, "bob", "DELETE", "/api/me/books/1", ""); code != http.StatusNotFound {
+		t.Errorf("Expected bob to be unable to remove alice's book, got %d", code)
+	}
+
+	// Shelving the same catalog book as bob creates a separate entry
+	if code, _ := shelfRequest(t, router, "bob", "PUT", "/api/me/books/1", `{"status":"to-read"}`); code != http.StatusCreated {
+		t.Errorf("Expected status 201 for bob, got %d", code)
+	}
+	_, resp = shelfRequest(t, router, "alice", "GET", "/api/me/books/1", "")
+	if item := resp.Data.(map[string]interface{}); item["status"] != "read" || item["notes"] != "mine" {
+		t.Errorf("Expected alice's entry to be unchanged, got %v", item)
+	}
+
+	_, alice := shelfRequest(t, router, "alice", "GET", "/api/me", "")
+	_, bob := shelfRequest(t, router, "bob", "GET", "/api/me", "")
+	if alice.Data.(map[string]interface{})["id"] == bob.Data.(map[string]interface{})["id"] {
+		t.Errorf("Expected distinct users, got %v and %v", alice.Data, bob.Data)
+	}
+	t.Logf("\n👥 alice and bob keep separate shelves")
+}
+
+func TestShelfRequiresAPrincipal(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	// With authentication off X-Actor still attributes changes, but never owns a shelf
+	headers := map[string]string{"Content-Type": "application/json", "X-Actor": "alice"}
+	for _, req := range []struct{ method, target, body string }{
+		{"GET", "/api/me", ""},
+		{"GET", "/api/me/books", ""},
+		{"PUT", "/api/me/books/1", `{"status":"read"}`},
+		{"DELETE", "/api/me/books/1", ""},
+	} {
+		rec, resp := doRequestWithHeaders(t, router, req.method, req.target, req.body, headers)
+		if rec.Code != http.StatusUnauthorized || resp.Code != constants.CodeUnauthorized {
+			t.Errorf("%s %s: expected 401 %s, got %d %s", req.method, req.target, constants.CodeUnauthorized, rec.Code, resp.Code)
+		}
+	}
+	if rec, _ := doRequestWithHeaders(t, router, "GET", "/api/books/1", "", headers); rec.Code != http.StatusOK {
+		t.Errorf("Expected the catalog to stay readable, got %d", rec.Code)
+	}
+}
+
+func TestShelfRejectsBadRequests(t *testing.T) {
+	t.Parallel()
+	router := newShelfTestRouter(t)
+
+	if rec, resp := doRequest(t, router, "GET", "/api/me/books", ""); rec.Code != http.StatusUnauthorized || resp.Code != constants.CodeUnauthorized {
+		t.Errorf("Expected 401 for an anonymous shelf, got %d %s", rec.Code, resp.Code)
+	}
+	if code, _ := shelfRequest(t, router, "alice", "PUT", "/api/me/books/99", `{"status":"read"}`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a book missing from the catalog, got %d", code)
+	}
+	if code, _ := shelfRequest(t, router, "alice", "PUT", "/api/me/books/abc", `{"status":"read"}`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid ID, got %d", code)
+	}
+	if code, _ := shelfRequest(t, router, "alice", "GET", "/api/me/books?status=done", ""); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid status filter, got %d", code)
+	}
+
+	future := time.Now().AddDate(1, 0, 0).Format(time.RFC3339)
+	code, resp := shelfRequest(t, router, "alice", "PUT", "/api/me/books/1", `{"status":"done","finished_at":"`+future+`"}`)
+	if got := fieldCodes(resp.Errors); code != http.StatusBadRequest || got["status"] != constants.FieldOneOf || got["finished_at"] != constants.FieldNotFuture {
+		t.Errorf("Expected oneof and notfuture field errors, got %d %+v", code, resp.Errors)
+	}
+
+	code, resp = shelfRequest(t, router, "alice", "PUT", "/api/me/books/1",
+		`{"status":"read","started_at":"2024-03-10T00:00:00Z","finished_at":"2024-03-01T00:00:00Z"}`)
+	if got := fieldCodes(resp.Errors); code != http.StatusBadRequest || got["finished_at"] != constants.FieldNotBefore {
+		t.Errorf("Expected a notbefore field error, got %d %+v", code, resp.Errors)
+	}
+
+	// The stored started_at counts when the request leaves it out
+	shelfRequest(t, router, "alice", "PUT", "/api/me/books/2", `{"status":"reading","started_at":"2024-03-10T00:00:00Z"}`)
+	code, resp = shelfRequest(t, router, "alice", "PUT", "/api/me/books/2", `{"status":"read","finished_at":"2024-03-01T00:00:00Z"}`)
+	if got := fieldCodes(resp.Errors); code != http.StatusBadRequest || got["finished_at"] != constants.FieldNotBefore {
+		t.Errorf("Expected a notbefore field error against the stored date, got %d %+v", code, resp.Errors)
+	}
+}
+
+func TestStoresScopeShelvesToTheirUser(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		alice, err := store.EnsureUser(ctx, "alice")
+		if err != nil {
+			t.Fatalf("%s: EnsureUser failed: %v", name, err)
+		}
+		if again, _ := store.EnsureUser(ctx, "alice"); again.ID != alice.ID {
+			t.Errorf("%s: expected EnsureUser to return the same user, got %d and %d", name, alice.ID, again.ID)
+		}
+		if found, err := store.GetUser(ctx, "alice"); err != nil || found.ID != alice.ID {
+			t.Errorf("%s: expected GetUser to find alice, got %+v (%v)", name, found, err)
+		}
+		if _, err := store.GetUser(ctx, "bob"); err != database.ErrUserNotFound {
+			t.Errorf("%s: expected ErrUserNotFound before bob's first use, got %v", name, err)
+		}
+		bob, _ := store.EnsureUser(ctx, "bob")
+
+		book, _ := store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: 1965, Status: "read"})
+		item, created, err := store.PutUserBook(ctx, alice.ID, book.ID, models.UserBookRequest{Status: "reading"})
+		if err != nil || !created || item.StartedAt == nil || item.Book.Title != "Dune" {
+			t.Fatalf("%s: expected a new shelf item, got %+v %v (%v)", name, item, created, err)
+		}
+		if _, err := store.GetUserBook(ctx, bob.ID, book.ID); err != database.ErrUserBookNotFound {
+			t.Errorf("%s: expected bob not to see alice's item, got %v", name, err)
+		}
+		if err := store.DeleteUserBook(ctx, bob.ID, book.ID); err != database.ErrUserBookNotFound {
+			t.Errorf("%s: expected bob not to remove alice's item, got %v", name, err)
+		}
+		if _, _, err := store.PutUserBook(ctx, alice.ID, 9999, models.UserBookRequest{Status: "read"}); err != database.ErrBookNotFound {
+			t.Errorf("%s: expected ErrBookNotFound for a missing book, got %v", name, err)
+		}
+
+		// Trashed books leave the shelf and purged ones are gone for good
+		store.Delete(ctx, book.ID, database.AnyVersion)
+		if items, _ := store.UserBooks(ctx, alice.ID); len(items) != 0 {
+			t.Errorf("%s: expected trashed book to be hidden from the shelf, got %d items", name, len(items))
+		}
+		store.Restore(ctx, book.ID)
+		if items, _ := store.UserBooks(ctx, alice.ID); len(items) != 1 {
+			t.Errorf("%s: expected restored book back on the shelf, got %d items", name, len(items))
+		}
+		store.Delete(ctx, book.ID, database.AnyVersion)
+		store.Purge(ctx, time.Now().Add(time.Minute))
+		store.Create(ctx, models.CreateBookRequest{Title: "Emma", Author: "Jane Austen", Year: 1815, Status: "read"})
+		if items, _ := store.UserBooks(ctx, alice.ID); len(items) != 0 {
+			t.Errorf("%s: expected purge to clear the shelf, got %d items", name, len(items))
+		}
+	}
+}
+
+func TestStoresDefaultTheCatalogStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		book, err := store.Create(ctx, models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: 1965})
+		if err != nil || book.Status != models.DefaultCatalogStatus {
+			t.Fatalf("%s: expected a book without status to be %s, got %+v (%v)", name, models.DefaultCatalogStatus, book, err)
+		}
+
+		read, err := store.Update(ctx, book.ID, models.UpdateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: 1965, Status: "read"}, database.AnyVersion)
+		if err != nil || read.Status != "read" {
+			t.Fatalf("%s: expected the status to be updated, got %+v (%v)", name, read, err)
+		}
+		kept, err := store.Update(ctx, book.ID, models.UpdateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: 1966}, database.AnyVersion)
+		if err != nil || kept.Status != "read" || kept.Year != 1966 {
+			t.Errorf("%s: expected an update without status to keep it, got %+v (%v)", name, kept, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"book-library-backend/models"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// newSortRouter returns a router over a small library with ties on author.
+func newSortRouter(t *testing.T) http.Handler {
+	t.Helper()
+	store := newTestStore(t)
+	for _, req := range []models.CreateBookRequest{
+		{Title: "Emma", Author: "Jane Austen", Year: 1815, Status: "read"},
+		{Title: "Persuasion", Author: "Jane Austen", Year: 1817, Status: "to-read"},
+		{Title: "Sense and Sensibility", Author: "Jane Austen", Year: 1811, Status: "reading"},
+	} {
+		if _, err := store.Create(context.Background(), req); err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+	}
+	return newTestRouterFor(t, store)
+}
+
+func TestSortDefaultIsByID(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	for i := 0; i < 5; i++ {
+		_, resp := doRequest(t, router, "GET", "/api/books", "")
+		if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[1 2 3 4 5 6]" {
+			t.Fatalf("Expected ID order on call %d, got %v", i, ids)
+		}
+	}
+}
+
+func TestSortMultiKey(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	rec, resp := doRequest(t, router, "GET", "/api/books?sort=author,-year,title", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// F. Scott Fitzgerald, George Orwell, Harper Lee, then Austen by year desc
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[3 2 1 5 4 6]" {
+		t.Errorf("Unexpected order %v", ids)
+	}
+	t.Logf("\n🔀 sort=author,-year,title → %v", bookIDs(t, resp))
+}
+
+func TestSortLegacyOrderBy(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	_, resp := doRequest(t, router, "GET", "/api/books?orderBy=year&orderDir=desc", "")
+	if ids := bookIDs(t, resp); fmt.Sprint(ids) != "[1 2 3 5 4 6]" {
+		t.Errorf("Unexpected order %v", ids)
+	}
+}
+
+func TestSortRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+	router := newSortRouter(t)
+
+	for _, target := range []string{
+		"/api/books?sort=publisher",
+		"/api/books?sort=title,-rating",
+		"/api/books?orderBy=pages",
+		"/api/books?orderBy=title&orderDir=sideways",
+	} {
+		if rec, _ := doRequest(t, router, "GET", target, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", target, rec.Code)
+		}
+	}
+}
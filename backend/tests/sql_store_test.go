@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSQLTestStore opens a SQLite store in a per-test temporary directory.
+func newSQLTestStore(t *testing.T, path string) *database.SQLStore {
+	t.Helper()
+	store, err := database.OpenSQLStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open SQL store: %v", err)
+	}
+	return store
+}
+
+func TestSQLStoreCRUD(t *testing.T) {
+	t.Parallel()
+	store := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer store.Close()
+	ctx := context.Background()
+
+	book, err := store.Create(ctx, models.CreateBookRequest{
+		Title: "SQL Book", Author: "SQL Author", Year: 2001, Description: "Stored on disk.", Status: "reading",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if book.Status != "reading" {
+		t.Errorf("Expected status reading, got %s", book.Status)
+	}
+
+	updated, err := store.Update(ctx, book.ID, models.UpdateBookRequest{
+		Title: "SQL Book 2", Author: "SQL Author", Year: 2002, Status: "read",
+	}, database.AnyVersion)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Title != "SQL Book 2" || updated.Status != "read" {
+		t.Errorf("Unexpected updated book: %+v", updated)
+	}
+
+	if err := store.Delete(ctx, book.ID, database.AnyVersion); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exists, _ := store.Exists(ctx, book.ID); exists {
+		t.Errorf("Expected book to be deleted")
+	}
+	if _, err := store.Update(ctx, book.ID, models.UpdateBookRequest{Title: "x", Author: "y", Year: 2000, Status: "read"}, database.AnyVersion); err == nil {
+		t.Errorf("Expected error updating deleted book")
+	}
+	t.Logf("\n💾 SQL store CRUD round-trip passed for ID %d", book.ID)
+}
+
+func TestSQLStorePersistsAcrossRestarts(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "library.db")
+	ctx := context.Background()
+
+	store := newSQLTestStore(t, path)
+	book, err := store.Create(ctx, models.CreateBookRequest{
+		Title: "Persistent", Author: "Disk", Year: 1999, Status: "to-read",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	before, _ := store.List(ctx)
+	store.Close()
+
+	reopened := newSQLTestStore(t, path)
+	defer reopened.Close()
+
+	found, err := reopened.Get(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("Expected book to survive restart, got %v", err)
+	}
+	if found.Title != "Persistent" || found.Status != "to-read" {
+		t.Errorf("Unexpected book after restart: %+v", found)
+	}
+	after, _ := reopened.List(ctx)
+	if len(after) != len(before) {
+		t.Errorf("Expected %d books after restart (no reseeding), got %d", len(before), len(after))
+	}
+	t.Logf("\n🔁 Book %d survived a restart", book.ID)
+}
+
+func TestSQLStoreWalkReleasesConnection(t *testing.T) {
+	t.Parallel()
+	store := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer store.Close()
+	ctx := context.Background()
+
+	batch := make([]models.BatchOperation, 1200)
+	for i := range batch {
+		batch[i] = models.BatchOperation{Op: models.BatchCreate, Book: models.CreateBookRequest{
+			Title: fmt.Sprintf("Walked %d", i), Author: "Walker", Year: 2000, Status: "to-read",
+		}}
+	}
+	if _, err := store.ApplyBatch(ctx, batch, true); err != nil {
+		t.Fatalf("Failed to create books: %v", err)
+	}
+
+	// Other store calls go through while fn runs, as an export writing to a
+	// slow client would
+	walked, lastID := 0, 0
+	err := store.Walk(ctx, func(book *models.Book) error {
+		if book.ID <= lastID {
+			t.Fatalf("Expected books in ID order, got %d after %d", book.ID, lastID)
+		}
+		lastID = book.ID
+		walked++
+		if walked%500 == 1 {
+			getCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			if _, err := store.Get(getCtx, book.ID); err != nil {
+				return fmt.Errorf("get during walk: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the walk to succeed, got %v", err)
+	}
+	if walked != 1203 {
+		t.Errorf("Expected 1203 books walked, got %d", walked)
+	}
+}
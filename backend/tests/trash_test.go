@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/database"
+	"book-library-backend/models"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteMovesBookToTrashAndRestore(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	if rec, _ := doRequest(t, router, "DELETE", "/api/books/2", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/2", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected trashed book to be hidden, got %d", rec.Code)
+	}
+	if _, list := doRequest(t, router, "GET", "/api/books", ""); len(bookIDs(t, list)) != 2 {
+		t.Errorf("Expected listing to exclude the trashed book, got %v", bookIDs(t, list))
+	}
+
+	rec, trash := doRequest(t, router, "GET", "/api/books/trash", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	items := trash.Data.([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["deleted_at"] == nil {
+		t.Fatalf("Expected one trashed book with deleted_at, got %v", items)
+	}
+
+	rec, restored := doRequest(t, router, "POST", "/api/books/2/restore", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data := restored.Data.(map[string]interface{})
+	if _, trashed := data["deleted_at"]; trashed || data["version"] != float64(3) || rec.Header().Get("ETag") != `"2.3"` {
+		t.Errorf("Unexpected restored book %v (ETag %s)", data, rec.Header().Get("ETag"))
+	}
+	if rec, _ := doRequest(t, router, "GET", "/api/books/2", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected restored book to be visible, got %d", rec.Code)
+	}
+	t.Logf("\n♻️ Restored book 2 from the trash")
+}
+
+func TestRestoreErrors(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	if rec, _ := doRequest(t, router, "POST", "/api/books/1/restore", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 restoring a live book, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "POST", "/api/books/9999/restore", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 restoring a missing book, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "DELETE", "/api/books/1", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec, _ := doRequest(t, router, "DELETE", "/api/books/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting a trashed book again, got %d", rec.Code)
+	}
+}
+
+func TestStoresTrashAndPurge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sqlStore := newSQLTestStore(t, filepath.Join(t.TempDir(), "library.db"))
+	defer sqlStore.Close()
+
+	stores := map[string]database.BookStore{"memory": newTestStore(t), "sqlite": sqlStore}
+	for name, store := range stores {
+		book, _ := store.Create(ctx, models.CreateBookRequest{Title: "Trashable", Author: "A", Year: 2000, Status: "read"})
+		if err := store.Delete(ctx, book.ID, database.AnyVersion); err != nil {
+			t.Fatalf("%s: delete failed: %v", name, err)
+		}
+
+		if exists, _ := store.Exists(ctx, book.ID); exists {
+			t.Errorf("%s: expected trashed book not to exist", name)
+		}
+		if results, _ := store.Search(ctx, "trashable"); len(results) != 0 {
+			t.Errorf("%s: expected trashed book to be unsearchable", name)
+		}
+		if _, err := store.Update(ctx, book.ID, models.UpdateBookRequest{Title: "X", Author: "A", Year: 2000, Status: "read"}, database.AnyVersion); err == nil || err.Error() != constants.ErrBookNotFound {
+			t.Errorf("%s: expected updating a trashed book to fail, got %v", name, err)
+		}
+		trash, _ := store.Trash(ctx)
+		if len(trash) != 1 || trash[0].ID != book.ID || trash[0].DeletedAt == nil {
+			t.Fatalf("%s: unexpected trash %v", name, trash)
+		}
+
+		// Purge only removes books deleted before the cutoff
+		if purged, err := store.Purge(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+			t.Errorf("%s: expected nothing purged, got %d (%v)", name, purged, err)
+		}
+		if purged, err := store.Purge(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+			t.Errorf("%s: expected one book purged, got %d (%v)", name, purged, err)
+		}
+		if _, err := store.Restore(ctx, book.ID); err == nil || err.Error() != constants.ErrBookNotFound {
+			t.Errorf("%s: expected purged book to be gone, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"book-library-backend/constants"
+	"book-library-backend/models"
+	"book-library-backend/utils"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fieldCodes maps each rejected field to the rule it failed
+func fieldCodes(fields []models.FieldError) map[string]string {
+	codes := make(map[string]string, len(fields))
+	for _, field := range fields {
+		codes[field.Field] = field.Code
+	}
+	return codes
+}
+
+func TestValidateBookRequestTags(t *testing.T) {
+	t.Parallel()
+	nextYear := time.Now().Year() + 1
+
+	cases := []struct {
+		name string
+		req  models.CreateBookRequest
+		want map[string]string
+	}{
+		{"valid", models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", ISBN: "0441172717", Year: 1965, Status: "read"}, map[string]string{}},
+		{"blank", models.CreateBookRequest{Title: "  "}, map[string]string{
+			"title": constants.FieldRequired, "author": constants.FieldRequired, "year": constants.FieldRequired,
+		}},
+		{"ranges", models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", ISBN: "123", Year: 999, Status: "lost"}, map[string]string{
+			"isbn": constants.FieldISBN, "year": constants.FieldMin, "status": constants.FieldOneOf,
+		}},
+		{"future", models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: nextYear, Status: "to-read"}, map[string]string{
+			"year": constants.FieldNotFuture,
+		}},
+		// The frontend form accepts the same bounds, 1000 to the current year
+		{"oldest year", models.CreateBookRequest{Title: "Beowulf", Author: "Unknown", Year: 1000}, map[string]string{}},
+		{"current year", models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: nextYear - 1}, map[string]string{}},
+	}
+	for _, c := range cases {
+		got := fieldCodes(utils.Validate(c.req))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+
+	// The update request carries the same tags
+	if got := fieldCodes(utils.Validate(models.UpdateBookRequest{Title: "Dune", Author: "Frank Herbert", Year: nextYear, Status: "read"})); got["year"] != constants.FieldNotFuture {
+		t.Errorf("Expected update year in the future to be rejected, got %v", got)
+	}
+	t.Logf("\n✅ Book request tags enforced")
+}
+
+func TestValidateCustomRule(t *testing.T) {
+	// Not parallel: registering a rule mutates the shared rule table
+	utils.RegisterRule("even", func(field string, value reflect.Value, _ string) string {
+		if value.Int()%2 != 0 {
+			return field + " must be even"
+		}
+		return ""
+	})
+
+	type request struct {
+		Count int    `json:"count" validate:"even,max=10"`
+		Code  string `json:"code,omitempty" validate:"omitempty,min=3"`
+		Skip  string `validate:"-"`
+	}
+	fields := utils.Validate(&request{Count: 3, Code: "ab"})
+	if got := fieldCodes(fields); !reflect.DeepEqual(got, map[string]string{"count": "even", "code": constants.FieldMin}) {
+		t.Fatalf("Unexpected field errors %v", fields)
+	}
+	if fields[0].Message != "count must be even" {
+		t.Errorf("Expected the rule message, got %q", fields[0].Message)
+	}
+	if fields := utils.Validate(request{Count: 12}); len(fields) != 1 || fields[0].Code != constants.FieldMax {
+		t.Errorf("Expected only max to fail, got %v", fields)
+	}
+	t.Logf("\n🧩 Custom rule applied: %s", fields[0].Message)
+}
+
+func TestProcessURLValidatesTags(t *testing.T) {
+	t.Parallel()
+	router := newTestRouter(t)
+
+	cases := []struct {
+		body string
+		want map[string]string
+	}{
+		{`{"operation":"canonical"}`, map[string]string{"url": constants.FieldRequired}},
+		{`{"url":"not a url","operation":"all"}`, map[string]string{"url": constants.FieldURL}},
+		{`{"url":"/relative/path","operation":"shrink"}`, map[string]string{"url": constants.FieldURL, "operation": constants.FieldOneOf}},
+	}
+	for i, c := range cases {
+		rec, resp := doRequest(t, router, "POST", "/api/process-url", c.body)
+		if rec.Code != http.StatusBadRequest || !reflect.DeepEqual(fieldCodes(resp.Errors), c.want) {
+			t.Errorf("case %d: expected 400 %v, got %d %v", i, c.want, rec.Code, resp.Errors)
+		}
+	}
+
+	rec, resp := doRequest(t, router, "POST", "/api/process-url", `{"url":"https://BYFOOD.com/Food/?x=1","operation":"all"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a valid request, got %d %q", rec.Code, resp.Error)
+	}
+
+	// Passes the url rule, which trims spaces, but does not parse as is
+	rec, resp = doRequest(t, router, "POST", "/api/process-url", `{"url":" https://byfood.com/food","operation":"all"}`)
+	if rec.Code != http.StatusBadRequest || resp.Code != constants.CodeInvalidURL {
+		t.Errorf("Expected 400 %s for an unparsable URL, got %d %s", constants.CodeInvalidURL, rec.Code, resp.Code)
+	}
+	t.Logf("\n🔗 URL requests validated by tags")
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"book-library-backend/constants"
+)
+
+// ErrInvalidISBN is returned by NormalizeISBN for malformed ISBNs
+var ErrInvalidISBN = errors.New(constants.ErrInvalidISBN)
+
+// NormalizeISBN validates an ISBN-10 or ISBN-13 and returns it as a bare
+// ISBN-13. Hyphens and spaces are ignored and a lowercase x is accepted as
+// the ISBN-10 check digit. An empty input stays empty.
+func NormalizeISBN(raw string) (string, error) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(raw))
+
+	switch {
+	case isbn == "":
+		return "", nil
+	case len(isbn) == 10 && ValidISBN10(isbn):
+		return ISBN10To13(isbn), nil
+	case len(isbn) == 13 && ValidISBN13(isbn):
+		return isbn, nil
+	default:
+		return "", ErrInvalidISBN
+	}
+}
+
+// ValidISBN10 reports whether isbn is ten digits (the last may be X) with a
+// valid mod-11 checksum
+func ValidISBN10(isbn string) bool {
+	if len(isbn) != 10 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var digit int
+		switch c := isbn[i]; {
+		case c >= '0' && c <= '9':
+			digit = int(c - '0')
+		case c == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += (10 - i) * digit
+	}
+	return sum%11 == 0
+}
+
+// ValidISBN13 reports whether isbn is thirteen digits with a valid mod-10
+// checksum
+func ValidISBN13(isbn string) bool {
+	if len(isbn) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := isbn[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(c-'0')
+	}
+	return sum%10 == 0
+}
+
+// ISBN10To13 converts a valid ISBN-10 to its 978-prefixed ISBN-13
+func ISBN10To13(isbn10 string) string {
+	isbn := "978" + isbn10[:9]
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(isbn[i]-'0')
+	}
+	return isbn + string(rune('0'+(10-sum%10)%10))
+}
@@ -2,11 +2,11 @@ package utils
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"book-library-backend/constants"
 	"book-library-backend/models"
 
 	"github.com/sirupsen/logrus"
@@ -21,13 +21,57 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, response models.AP
 	}
 }
 
-func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
+// ProblemContentType is the RFC 7807 media type clients can ask for in
+// Accept to receive errors as problem details instead of the envelope
+const ProblemContentType = "application/problem+json"
+
+// WriteError reports apiErr with its status and code, as problem details
+// when the request accepts application/problem+json and in the usual
+// envelope otherwise.
+func WriteError(w http.ResponseWriter, r *http.Request, apiErr *models.APIError) {
+	if r != nil && acceptsProblemJSON(r) {
+		problem := models.ProblemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(apiErr.Status),
+			Status:   apiErr.Status,
+			Detail:   apiErr.Message,
+			Instance: r.URL.Path,
+			Code:     apiErr.Code,
+			Errors:   apiErr.Fields,
+			Data:     apiErr.Data,
+		}
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(apiErr.Status)
+		if err := json.NewEncoder(w).Encode(problem); err != nil {
+			logrus.WithError(err).Error("Failed to encode problem details")
+		}
+		return
+	}
+
 	response := models.APIResponse{
 		Success: false,
 		Message: "Error",
-		Error:   message,
+		Data:    apiErr.Data,
+		Error:   apiErr.Message,
+		Code:    apiErr.Code,
+		Errors:  apiErr.Fields,
+	}
+	WriteJSONResponse(w, apiErr.Status, response)
+}
+
+// acceptsProblemJSON reports whether the Accept header lists problem+json
+func acceptsProblemJSON(r *http.Request) bool {
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
 	}
-	WriteJSONResponse(w, statusCode, response)
+	return false
 }
 
 func WriteSuccessResponse(w http.ResponseWriter, message string, data interface{}) {
@@ -39,6 +83,17 @@ func WriteSuccessResponse(w http.ResponseWriter, message string, data interface{
 	WriteJSONResponse(w, http.StatusOK, response)
 }
 
+func WritePaginatedResponse(w http.ResponseWriter, message string, data interface{}, pagination *models.Pagination, facets *models.Facets) {
+	response := models.APIResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+		Facets:     facets,
+	}
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
 func ParseIDFromPath(path string) (int, error) {
 	parts := strings.Split(path, "/")
 	if len(parts) < 3 {
@@ -48,33 +103,3 @@ func ParseIDFromPath(path string) (int, error) {
 	idStr := parts[len(parts)-1]
 	return strconv.Atoi(idStr)
 }
-
-func ValidateBook(book models.CreateBookRequest) []string {
-	var errors []string
-
-	if strings.TrimSpace(book.Title) == "" {
-		errors = append(errors, constants.ErrInvalidTitle)
-	}
-
-	if strings.TrimSpace(book.Author) == "" {
-		errors = append(errors, constants.ErrInvalidAuthor)
-	}
-
-	if book.Year < 1000 || book.Year > 2100 {
-		errors = append(errors, constants.ErrInvalidYear)
-	}
-
-	validStatuses := []string{"to-read", "reading", "read"}
-	statusValid := false
-	for _, validStatus := range validStatuses {
-		if book.Status == validStatus {
-			statusValid = true
-			break
-		}
-	}
-	if !statusValid {
-		errors = append(errors, "Status must be one of: to-read, reading, read")
-	}
-
-	return errors
-}
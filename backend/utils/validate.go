@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"book-library-backend/constants"
+	"book-library-backend/models"
+)
+
+// Rule checks one field against a validate tag rule. param is the text after
+// "=" in the tag and is empty for rules without one. It returns the message
+// to report, or "" when the value passes.
+type Rule func(field string, value reflect.Value, param string) string
+
+// rules holds the rules usable in validate tags besides omitempty, which
+// skips the remaining rules of a field left at its zero value
+var rules = map[string]Rule{
+	constants.FieldRequired:  ruleRequired,
+	constants.FieldMin:       ruleMin,
+	constants.FieldMax:       ruleMax,
+	constants.FieldOneOf:     ruleOneOf,
+	constants.FieldURL:       ruleURL,
+	constants.FieldISBN:      ruleISBN,
+	constants.FieldNotFuture: ruleNotFuture,
+}
+
+// RegisterRule makes rule available to validate tags under name. It is not
+// safe for concurrent use and is meant to be called from init functions.
+func RegisterRule(name string, rule Rule) {
+	rules[name] = rule
+}
+
+// Validate checks the exported fields of the struct v points to against
+// their validate tags and reports at most one error, the first failed rule,
+// per field. Fields are named after their JSON key. An unknown rule or a
+// malformed parameter is a programming error and panics.
+func Validate(v interface{}) []models.FieldError {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	typ := value.Type()
+
+	var errors []models.FieldError
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("validate")
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+
+		name := field.Name
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+
+		if err, failed := checkField(name, value.Field(i), tag); failed {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+// checkField applies the comma-separated rules of tag to one field. Pointer
+// fields are checked through their target; a nil pointer counts as blank.
+func checkField(name string, value reflect.Value, tag string) (models.FieldError, bool) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value = reflect.Zero(value.Type().Elem())
+		} else {
+			value = value.Elem()
+		}
+	}
+	for _, spec := range strings.Split(tag, ",") {
+		ruleName, param, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		if ruleName == "omitempty" {
+			if isBlank(value) {
+				return models.FieldError{}, false
+			}
+			continue
+		}
+
+		rule, ok := rules[ruleName]
+		if !ok {
+			panic(fmt.Sprintf("validate: unknown rule %q on field %s", ruleName, name))
+		}
+		if message := rule(name, value, param); message != "" {
+			return models.FieldError{Field: name, Code: ruleName, Message: message}, true
+		}
+	}
+	return models.FieldError{}, false
+}
+
+// isBlank reports whether value is its zero value; strings made only of
+// whitespace count as blank
+func isBlank(value reflect.Value) bool {
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	return value.IsZero()
+}
+
+func ruleRequired(field string, value reflect.Value, _ string) string {
+	if isBlank(value) {
+		return fmt.Sprintf(constants.ErrFieldRequired, field)
+	}
+	return ""
+}
+
+func ruleMin(field string, value reflect.Value, param string) string {
+	limit := intParam(field, param)
+	switch {
+	case value.Kind() == reflect.String && int64(len([]rune(value.String()))) < limit:
+		return fmt.Sprintf(constants.ErrFieldMinLength, field, limit)
+	case isInt(value) && value.Int() < limit:
+		return fmt.Sprintf(constants.ErrFieldMin, field, limit)
+	}
+	return ""
+}
+
+func ruleMax(field string, value reflect.Value, param string) string {
+	limit := intParam(field, param)
+	switch {
+	case value.Kind() == reflect.String && int64(len([]rune(value.String()))) > limit:
+		return fmt.Sprintf(constants.ErrFieldMaxLength, field, limit)
+	case isInt(value) && value.Int() > limit:
+		return fmt.Sprintf(constants.ErrFieldMax, field, limit)
+	}
+	return ""
+}
+
+func ruleOneOf(field string, value reflect.Value, param string) string {
+	options := strings.Fields(param)
+	actual := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if actual == option {
+			return ""
+		}
+	}
+	return fmt.Sprintf(constants.ErrFieldOneOf, field, strings.Join(options, ", "))
+}
+
+// ruleURL accepts absolute URLs with a scheme and a host
+func ruleURL(field string, value reflect.Value, _ string) string {
+	parsed, err := url.ParseRequestURI(strings.TrimSpace(value.String()))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Sprintf(constants.ErrFieldURL, field)
+	}
+	return ""
+}
+
+func ruleISBN(field string, value reflect.Value, _ string) string {
+	if _, err := NormalizeISBN(value.String()); err != nil {
+		return fmt.Sprintf(constants.ErrFieldISBN, field)
+	}
+	return ""
+}
+
+// ruleNotFuture rejects years after the current one and future times
+func ruleNotFuture(field string, value reflect.Value, _ string) string {
+	now := time.Now()
+	switch {
+	case isInt(value) && value.Int() > int64(now.Year()):
+		return fmt.Sprintf(constants.ErrFieldNotFuture, field)
+	case value.Type() == reflect.TypeOf(time.Time{}) && value.Interface().(time.Time).After(now):
+		return fmt.Sprintf(constants.ErrFieldNotFuture, field)
+	}
+	return ""
+}
+
+func isInt(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func intParam(field, param string) int64 {
+	limit, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid parameter %q on field %s", param, field))
+	}
+	return limit
+}
@@ -2,7 +2,7 @@
 
 import React, { useState, useEffect } from 'react';
 import { Book, BookFormData, FormErrors } from '@/types/book';
-import { validateBookForm } from '@/utils/validation';
+import { MIN_BOOK_YEAR, maxBookYear, validateBookForm } from '@/utils/validation';
 
 interface BookFormProps {
   book?: Book | null;
@@ -127,15 +127,15 @@ export const BookForm: React.FC<BookFormProps> = ({ book, onSubmit, onCancel, lo
             errors.year ? 'border-red-500' : 'border-gray-300'
           }`}
           placeholder="Enter publication year"
-          min="1000"
-          max="2100"
+          min={MIN_BOOK_YEAR}
+          max={maxBookYear()}
         />
         {errors.year && <p className="mt-1 text-sm text-red-600">{errors.year}</p>}
       </div>
 
       <div>
         <label htmlFor="status" className="block text-sm font-medium text-gray-700 mb-2">
-          Reading Status *
+          Library Status
         </label>
         <select
           id="status"
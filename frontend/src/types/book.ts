@@ -14,7 +14,8 @@ export interface CreateBookRequest {
   author: string;
   year: number;
   description: string;
-  status: 'to-read' | 'reading' | 'read';
+  // Deprecated library-wide status, personal status lives on /api/me/books
+  status?: 'to-read' | 'reading' | 'read';
 }
 
 export interface UpdateBookRequest {
@@ -22,7 +23,8 @@ export interface UpdateBookRequest {
   author: string;
   year: number;
   description: string;
-  status: 'to-read' | 'reading' | 'read';
+  // Deprecated library-wide status, personal status lives on /api/me/books
+  status?: 'to-read' | 'reading' | 'read';
 }
 
 export interface APIResponse<T = any> {
@@ -1,5 +1,9 @@
 import { BookFormData, FormErrors } from '@/types/book';
 
+// Same bounds as the server's year rule (min=1000,notfuture)
+export const MIN_BOOK_YEAR = 1000;
+export const maxBookYear = (): number => new Date().getFullYear();
+
 export const validateBookForm = (data: BookFormData): FormErrors => {
   const errors: FormErrors = {};
 
@@ -18,15 +22,15 @@ export const validateBookForm = (data: BookFormData): FormErrors => {
   }
 
   // Year validation
-  const currentYear = new Date().getFullYear();
+  const maxYear = maxBookYear();
   const year = parseInt(data.year);
   
   if (!data.year.trim()) {
     errors.year = 'Year is required';
   } else if (isNaN(year)) {
     errors.year = 'Year must be a valid number';
-  } else if (year < 1000 || year > currentYear + 10) {
-    errors.year = `Year must be between 1000 and ${currentYear + 10}`;
+  } else if (year < MIN_BOOK_YEAR || year > maxYear) {
+    errors.year = `Year must be between ${MIN_BOOK_YEAR} and ${maxYear}`;
   }
 
   // Description validation (optional but with length limit)
@@ -34,11 +38,9 @@ export const validateBookForm = (data: BookFormData): FormErrors => {
     errors.description = 'Description must be less than 1000 characters';
   }
 
-  // Status validation
+  // Status validation (optional, defaults to to-read on the server)
   const validStatuses = ['to-read', 'reading', 'read'];
-  if (!data.status) {
-    errors.status = 'Reading status is required';
-  } else if (!validStatuses.includes(data.status)) {
+  if (data.status && !validStatuses.includes(data.status)) {
     errors.status = 'Invalid reading status';
   }
 